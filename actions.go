@@ -0,0 +1,253 @@
+package plunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// An action sends a template to a contact after they trigger one of its events.
+type Action struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	RunOnce    bool    `json:"runOnce"`
+	Delay      int     `json:"delay"`
+	TemplateID string  `json:"templateId"`
+	Events     []Event `json:"events"`    // the events that trigger the action
+	NotEvents  []Event `json:"notevents"` // the action is skipped for contacts that triggered any of these
+	ProjectID  string  `json:"projectId"`
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+type ActionPayload struct {
+	Name      string   `json:"name"`
+	RunOnce   bool     `json:"runOnce"`   // Only run the action once per contact.
+	Delay     int      `json:"delay"`     // Minutes to wait after the event before sending the template.
+	Template  string   `json:"template"`  // ID of the template to send.
+	Events    []string `json:"events"`    // IDs of the events that trigger the action.
+	NotEvents []string `json:"notevents"` // IDs of the events that stop the action from running.
+}
+
+var (
+	ErrMissingActionID      = errors.New("missing action id")
+	ErrMissingActionName    = errors.New("missing action name")
+	ErrMissingEvents        = errors.New("missing trigger events")
+	ErrCouldNotCreateAction = errors.New("could not create action")
+)
+
+func (a ActionPayload) validate() error {
+	if a.Name == "" {
+		return ErrMissingActionName
+	}
+
+	if a.Template == "" {
+		return ErrMissingTemplateID
+	}
+
+	if len(a.Events) == 0 {
+		return ErrMissingEvents
+	}
+
+	if a.Delay < 0 {
+		return ErrInvalidDelay
+	}
+
+	return nil
+}
+
+// Reports whether triggering the event with the given ID runs the action.
+func (a *Action) TriggeredBy(eventID string) bool {
+	for _, e := range a.Events {
+		if e.ID == eventID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Creates an action that sends a template whenever a contact triggers one of its events.
+func (p *Plunk) CreateAction(payload ActionPayload) (*Action, error) {
+	return p.CreateActionContext(context.Background(), payload)
+}
+
+// Like CreateAction, but the request is bound to ctx.
+func (p *Plunk) CreateActionContext(ctx context.Context, payload ActionPayload) (*Action, error) {
+	if err := payload.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &Action{}
+	url := p.url(actionsEndpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodPost,
+		Body:   payload.normalize(),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.ID == "" {
+		return nil, ErrCouldNotCreateAction
+	}
+
+	p.logInfo("action created", "id", result.ID)
+
+	return result, nil
+}
+
+// Gets the details of a specific action.
+func (p *Plunk) GetAction(id string) (*Action, error) {
+	return p.GetActionContext(context.Background(), id)
+}
+
+// Like GetAction, but the request is bound to ctx.
+func (p *Plunk) GetActionContext(ctx context.Context, id string) (*Action, error) {
+	if id == "" {
+		return nil, ErrMissingActionID
+	}
+
+	result := &Action{}
+	endpoint := fmt.Sprintf("%s/%s", actionsEndpoint, id)
+	url := p.url(endpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodGet,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("action retrieved", "id", id)
+
+	return result, nil
+}
+
+// Get a list of all actions in your Plunk project.
+func (p *Plunk) GetActions() ([]*Action, error) {
+	return p.GetActionsContext(context.Background())
+}
+
+// Like GetActions, but the request is bound to ctx.
+func (p *Plunk) GetActionsContext(ctx context.Context) ([]*Action, error) {
+	result := []*Action{}
+	url := p.url(actionsEndpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodGet,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("actions retrieved", "count", len(result))
+
+	return result, nil
+}
+
+// Replaces the settings, template and events of an action.
+func (p *Plunk) UpdateAction(id string, payload ActionPayload) (*Action, error) {
+	return p.UpdateActionContext(context.Background(), id, payload)
+}
+
+// Like UpdateAction, but the request is bound to ctx.
+func (p *Plunk) UpdateActionContext(ctx context.Context, id string, payload ActionPayload) (*Action, error) {
+	if id == "" {
+		return nil, ErrMissingActionID
+	}
+
+	if err := payload.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &Action{}
+	url := p.url(actionsEndpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodPut,
+		Body: struct {
+			ID string `json:"id"`
+			ActionPayload
+		}{id, payload.normalize()},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("action updated", "id", id)
+
+	return result, nil
+}
+
+// Deletes an action. The events and template it uses are kept.
+func (p *Plunk) DeleteAction(id string) (*Action, error) {
+	return p.DeleteActionContext(context.Background(), id)
+}
+
+// Like DeleteAction, but the request is bound to ctx.
+func (p *Plunk) DeleteActionContext(ctx context.Context, id string) (*Action, error) {
+	if id == "" {
+		return nil, ErrMissingActionID
+	}
+
+	url := p.url(actionsEndpoint)
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodDelete,
+		Body:   map[string]string{"id": id},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Action{}
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("action deleted", "id", id)
+
+	return result, nil
+}
+
+// The API expects an empty list rather than null when there are no "not" events.
+func (a ActionPayload) normalize() ActionPayload {
+	if a.NotEvents == nil {
+		a.NotEvents = []string{}
+	}
+
+	return a
+}
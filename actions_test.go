@@ -0,0 +1,195 @@
+package plunk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates the template and events an action needs, returning their IDs.
+func createActionDeps(t *testing.T, p *Plunk) (templateID string, signup string, churn string) {
+	t.Helper()
+
+	template, err := p.CreateTemplate(TemplatePayload{
+		Subject: "Welcome",
+		Body:    "# Welcome aboard",
+		Type:    TemplateTypeMarketing,
+	})
+	assert.Nil(t, err)
+
+	resp, err := p.TriggerEvent(EventPayload{Event: "signup", Email: eventTestEmail})
+	assert.Nil(t, err)
+	signup = resp.Event
+
+	resp, err = p.TriggerEvent(EventPayload{Event: "churn", Email: eventTestEmail})
+	assert.Nil(t, err)
+	churn = resp.Event
+
+	return template.ID, signup, churn
+}
+
+func TestCreateAction(t *testing.T) {
+	p, srv := newTestClient(t)
+	templateID, signup, churn := createActionDeps(t, p)
+
+	action, err := p.CreateAction(ActionPayload{
+		Name:      "Welcome series",
+		RunOnce:   true,
+		Delay:     60,
+		Template:  templateID,
+		Events:    []string{signup},
+		NotEvents: []string{churn},
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, action.ID)
+	assert.Equal(t, "Welcome series", action.Name)
+	assert.True(t, action.RunOnce)
+	assert.Equal(t, 60, action.Delay)
+	assert.Equal(t, templateID, action.TemplateID)
+	assert.Len(t, action.Events, 1)
+	assert.Equal(t, "signup", action.Events[0].Name)
+	assert.Len(t, action.NotEvents, 1)
+	assert.Equal(t, "churn", action.NotEvents[0].Name)
+
+	assert.True(t, action.TriggeredBy(signup))
+	assert.False(t, action.TriggeredBy(churn))
+
+	actions := srv.Actions()
+	assert.Len(t, actions, 1)
+	assert.Equal(t, []string{signup}, actions[0].Events)
+
+	// no "not" events are sent as an empty list
+	action, err = p.CreateAction(ActionPayload{
+		Name:     "Signup ping",
+		Template: templateID,
+		Events:   []string{signup},
+	})
+	assert.Nil(t, err)
+	assert.Empty(t, action.NotEvents)
+
+	testCases := []struct {
+		payload ActionPayload
+		err     error
+	}{
+		{
+			payload: ActionPayload{Template: templateID, Events: []string{signup}},
+			err:     ErrMissingActionName,
+		},
+		{
+			payload: ActionPayload{Name: "Name", Events: []string{signup}},
+			err:     ErrMissingTemplateID,
+		},
+		{
+			payload: ActionPayload{Name: "Name", Template: templateID},
+			err:     ErrMissingEvents,
+		},
+		{
+			payload: ActionPayload{Name: "Name", Template: templateID, Events: []string{signup}, Delay: -1},
+			err:     ErrInvalidDelay,
+		},
+	}
+
+	for _, tc := range testCases {
+		action, err := p.CreateAction(tc.payload)
+		assert.Equal(t, tc.err, err)
+		assert.Nil(t, action)
+	}
+
+	_, err = p.CreateAction(ActionPayload{
+		Name:     "Name",
+		Template: templateID,
+		Events:   []string{"unknown"},
+	})
+	assert.Equal(t, "Plunk Error (Code: 404, Error: Not Found, Message: That event was not found)", err.Error())
+}
+
+func TestGetActions(t *testing.T) {
+	p, _ := newTestClient(t)
+	templateID, signup, _ := createActionDeps(t, p)
+
+	actions, err := p.GetActions()
+	assert.Nil(t, err)
+	assert.Empty(t, actions)
+
+	created, err := p.CreateAction(ActionPayload{
+		Name:     "Welcome series",
+		Template: templateID,
+		Events:   []string{signup},
+	})
+	assert.Nil(t, err)
+
+	action, err := p.GetAction(created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, created, action)
+
+	actions, err = p.GetActions()
+	assert.Nil(t, err)
+	assert.Equal(t, []*Action{created}, actions)
+
+	_, err = p.GetAction("")
+	assert.Equal(t, ErrMissingActionID, err)
+
+	_, err = p.GetAction("unknown")
+	assert.Equal(t, "Plunk Error (Code: 404, Error: Not Found, Message: That action was not found)", err.Error())
+}
+
+func TestUpdateAction(t *testing.T) {
+	p, _ := newTestClient(t)
+	templateID, signup, churn := createActionDeps(t, p)
+
+	created, err := p.CreateAction(ActionPayload{
+		Name:     "Welcome series",
+		Template: templateID,
+		Events:   []string{signup},
+	})
+	assert.Nil(t, err)
+
+	action, err := p.UpdateAction(created.ID, ActionPayload{
+		Name:     "Win back",
+		Delay:    1440,
+		Template: templateID,
+		Events:   []string{churn},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, created.ID, action.ID)
+	assert.Equal(t, "Win back", action.Name)
+	assert.Equal(t, 1440, action.Delay)
+	assert.True(t, action.TriggeredBy(churn))
+	assert.False(t, action.TriggeredBy(signup))
+
+	_, err = p.UpdateAction("", ActionPayload{Name: "Name", Template: templateID, Events: []string{signup}})
+	assert.Equal(t, ErrMissingActionID, err)
+
+	_, err = p.UpdateAction(created.ID, ActionPayload{Template: templateID, Events: []string{signup}})
+	assert.Equal(t, ErrMissingActionName, err)
+
+	_, err = p.UpdateAction("unknown", ActionPayload{Name: "Name", Template: templateID, Events: []string{signup}})
+	assert.Equal(t, "Plunk Error (Code: 404, Error: Not Found, Message: That action was not found)", err.Error())
+}
+
+func TestDeleteAction(t *testing.T) {
+	p, srv := newTestClient(t)
+	templateID, signup, _ := createActionDeps(t, p)
+
+	created, err := p.CreateAction(ActionPayload{
+		Name:     "Welcome series",
+		Template: templateID,
+		Events:   []string{signup},
+	})
+	assert.Nil(t, err)
+
+	action, err := p.DeleteAction(created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, created.ID, action.ID)
+	assert.Empty(t, srv.Actions())
+
+	// the template and events outlive the action
+	_, err = p.GetTemplate(templateID)
+	assert.Nil(t, err)
+
+	_, err = p.DeleteAction("")
+	assert.Equal(t, ErrMissingActionID, err)
+
+	_, err = p.DeleteAction(created.ID)
+	assert.Equal(t, "Plunk Error (Code: 404, Error: Not Found, Message: That action was not found)", err.Error())
+}
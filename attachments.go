@@ -0,0 +1,128 @@
+package plunk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// The most attachment data a single email may carry, before base64 encoding.
+const MaxAttachmentSize = 10 << 20
+
+// A file sent along with a transactional email.
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"` // detected from Content when empty
+	Content     []byte `json:"content"`     // sent base64 encoded
+}
+
+var (
+	ErrMissingFilename    = errors.New("missing attachment filename")
+	ErrEmptyAttachment    = errors.New("empty attachment")
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+)
+
+// AttachmentSizeError is returned when attachments exceed MaxAttachmentSize,
+// before anything is sent. It matches ErrAttachmentTooLarge.
+type AttachmentSizeError struct {
+	Index    int    // position of the payload in the batch, 0 for single payloads
+	Filename string // the attachment that crossed the limit
+	Size     int64  // bytes of attachment data, up to and including Filename
+	Limit    int64
+}
+
+func (e *AttachmentSizeError) Error() string {
+	return fmt.Sprintf("attachments of payload %d are too large: %q brings them to %d bytes, over the limit of %d", e.Index, e.Filename, e.Size, e.Limit)
+}
+
+func (e *AttachmentSizeError) Is(target error) bool {
+	return target == ErrAttachmentTooLarge
+}
+
+// Reads an attachment from r and detects its content type. Reading stops with
+// an *AttachmentSizeError as soon as it exceeds MaxAttachmentSize.
+func NewAttachment(filename string, r io.Reader) (Attachment, error) {
+	if filename == "" {
+		return Attachment{}, ErrMissingFilename
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, MaxAttachmentSize+1))
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	if len(content) > MaxAttachmentSize {
+		return Attachment{}, &AttachmentSizeError{Filename: filename, Size: int64(len(content)), Limit: MaxAttachmentSize}
+	}
+
+	return Attachment{
+		Filename:    filename,
+		ContentType: detectContentType(filename, content),
+		Content:     content,
+	}, nil
+}
+
+// Like NewAttachment, but reads the file at path and names the attachment
+// after it.
+func NewAttachmentFromFile(path string) (Attachment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Attachment{}, err
+	}
+	defer f.Close()
+
+	return NewAttachment(filepath.Base(path), f)
+}
+
+// Sniffs the content type of content, falling back on the file extension for
+// formats http.DetectContentType does not know, e.g. CSV.
+func detectContentType(filename string, content []byte) string {
+	contentType := http.DetectContentType(content)
+	if contentType != "application/octet-stream" {
+		return contentType
+	}
+
+	if byExtension := mime.TypeByExtension(filepath.Ext(filename)); byExtension != "" {
+		return byExtension
+	}
+
+	return contentType
+}
+
+// Checks the attachments of the payload at index, and returns a copy of them
+// with every content type filled in.
+func checkAttachments(index int, attachments []Attachment) ([]Attachment, error) {
+	if len(attachments) == 0 {
+		return attachments, nil
+	}
+
+	result := make([]Attachment, len(attachments))
+
+	var size int64
+	for i, a := range attachments {
+		if a.Filename == "" {
+			return nil, ErrMissingFilename
+		}
+
+		if len(a.Content) == 0 {
+			return nil, ErrEmptyAttachment
+		}
+
+		size += int64(len(a.Content))
+		if size > MaxAttachmentSize {
+			return nil, &AttachmentSizeError{Index: index, Filename: a.Filename, Size: size, Limit: MaxAttachmentSize}
+		}
+
+		if a.ContentType == "" {
+			a.ContentType = detectContentType(a.Filename, a.Content)
+		}
+
+		result[i] = a
+	}
+
+	return result, nil
+}
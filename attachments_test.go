@@ -0,0 +1,131 @@
+package plunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPDF = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+
+func TestNewAttachment(t *testing.T) {
+	a, err := NewAttachment("invoice.pdf", bytes.NewReader(testPDF))
+	assert.Nil(t, err)
+	assert.Equal(t, "invoice.pdf", a.Filename)
+	assert.Equal(t, "application/pdf", a.ContentType)
+	assert.Equal(t, testPDF, a.Content)
+
+	a, err = NewAttachment("receipt.txt", strings.NewReader("Thank you for your order"))
+	assert.Nil(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", a.ContentType)
+
+	// formats that cannot be sniffed fall back on the extension
+	a, err = NewAttachment("data.json", bytes.NewReader([]byte{0x00, 0x01, 0x02}))
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json", a.ContentType)
+
+	_, err = NewAttachment("", strings.NewReader("content"))
+	assert.Equal(t, ErrMissingFilename, err)
+
+	_, err = NewAttachment("huge.bin", bytes.NewReader(make([]byte, MaxAttachmentSize+100)))
+	var sizeErr *AttachmentSizeError
+	assert.True(t, errors.As(err, &sizeErr))
+	assert.True(t, errors.Is(err, ErrAttachmentTooLarge))
+	assert.Equal(t, "huge.bin", sizeErr.Filename)
+	assert.Equal(t, int64(MaxAttachmentSize), sizeErr.Limit)
+}
+
+func TestNewAttachmentFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invoice.pdf")
+	assert.Nil(t, os.WriteFile(path, testPDF, 0o644))
+
+	a, err := NewAttachmentFromFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "invoice.pdf", a.Filename)
+	assert.Equal(t, "application/pdf", a.ContentType)
+
+	_, err = NewAttachmentFromFile(filepath.Join(t.TempDir(), "missing.pdf"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestAttachmentJSON(t *testing.T) {
+	b, err := json.Marshal(Attachment{Filename: "a.txt", ContentType: "text/plain", Content: []byte("hello")})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"filename":"a.txt","contentType":"text/plain","content":"aGVsbG8="}`, string(b))
+}
+
+func TestSendTransactionalEmailWithAttachments(t *testing.T) {
+	p, server := newTestClient(t)
+
+	invoice, err := NewAttachment("invoice.pdf", bytes.NewReader(testPDF))
+	assert.Nil(t, err)
+
+	attachments := []Attachment{invoice, {Filename: "notes.txt", Content: []byte("see attached")}}
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:          []string{"test@example.com"},
+		Subject:     "Your invoice",
+		Body:        "Your invoice is attached.",
+		Attachments: attachments,
+	})
+	assert.Nil(t, err)
+
+	sent := server.Emails()[0].Attachments
+	assert.Len(t, sent, 2)
+	assert.Equal(t, "invoice.pdf", sent[0].Filename)
+	assert.Equal(t, "application/pdf", sent[0].ContentType)
+	assert.Equal(t, testPDF, sent[0].Content)
+	assert.Equal(t, "text/plain; charset=utf-8", sent[1].ContentType)
+
+	// the detected content type is not written back to the caller's slice
+	assert.Equal(t, "", attachments[1].ContentType)
+}
+
+func TestSendTransactionalEmailAttachmentLimits(t *testing.T) {
+	p, server := newTestClient(t)
+
+	half := make([]byte, MaxAttachmentSize/2+1)
+	_, err := p.SendTransactionalEmailBatch([]TransactionalEmailPayload{
+		{To: []string{"a@example.com"}, Subject: "Subject", Body: "Body"},
+		{
+			To:      []string{"b@example.com"},
+			Subject: "Subject",
+			Body:    "Body",
+			Attachments: []Attachment{
+				{Filename: "first.bin", Content: half},
+				{Filename: "second.bin", Content: half},
+			},
+		},
+	})
+
+	var sizeErr *AttachmentSizeError
+	assert.True(t, errors.As(err, &sizeErr))
+	assert.Equal(t, 1, sizeErr.Index)
+	assert.Equal(t, "second.bin", sizeErr.Filename)
+	assert.Equal(t, int64(2*len(half)), sizeErr.Size)
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:          []string{"a@example.com"},
+		Subject:     "Subject",
+		Body:        "Body",
+		Attachments: []Attachment{{Filename: "empty.txt"}},
+	})
+	assert.Equal(t, ErrEmptyAttachment, err)
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:          []string{"a@example.com"},
+		Subject:     "Subject",
+		Body:        "Body",
+		Attachments: []Attachment{{Content: []byte("content")}},
+	})
+	assert.Equal(t, ErrMissingFilename, err)
+
+	// nothing is sent when any attachment is rejected
+	assert.Empty(t, server.Emails())
+}
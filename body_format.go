@@ -0,0 +1,100 @@
+package plunk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// BodyFormat tells the client how to read the Body of a transactional email.
+// Plunk itself guesses: a body starting with # is Markdown, anything else is
+// HTML. Setting a format makes the body render the same whatever it starts
+// with.
+type BodyFormat string
+
+const (
+	// Rendered to HTML by the client, with GitHub Flavored Markdown. Raw HTML
+	// in the Markdown is left out. Plunk's Markdown styling is not applied.
+	BodyFormatMarkdown BodyFormat = "markdown"
+	// Sent as is, wrapped in a <div> when it starts with # so that Plunk does
+	// not read it as Markdown.
+	BodyFormatHTML BodyFormat = "html"
+	// Escaped and sent as HTML, with line breaks kept.
+	BodyFormatText BodyFormat = "text"
+)
+
+var ErrUnknownBodyFormat = errors.New("unknown body format")
+
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Preview returns the body of the email as HTML, the way it is sent with its
+// BodyFormat. Bodies without a format are previewed the way Plunk guesses
+// theirs, except for the styling Plunk applies to Markdown, so previews can
+// be snapshot tested.
+func (pl TransactionalEmailPayload) Preview() (string, error) {
+	if pl.BodyFormat == "" && startsWithHeading(pl.Body) {
+		return renderMarkdown(pl.Body)
+	}
+
+	body, _, err := pl.formatBody()
+	return body, err
+}
+
+// Returns the body and text of the payload as they are sent to Plunk. Bodies
+// in a format other than HTML are their own text fallback, unless Text is set.
+func (pl TransactionalEmailPayload) formatBody() (string, string, error) {
+	body, text := pl.Body, pl.Text
+
+	switch pl.BodyFormat {
+	case "":
+		return body, text, nil
+
+	case BodyFormatMarkdown:
+		if text == "" {
+			text = body
+		}
+
+		body, err := renderMarkdown(body)
+		return body, text, err
+
+	case BodyFormatHTML:
+		if text == "" {
+			text = htmlToText(body)
+		}
+
+		if startsWithHeading(body) {
+			body = "<div>" + body + "</div>"
+		}
+
+		return body, text, nil
+
+	case BodyFormatText:
+		if text == "" {
+			text = body
+		}
+
+		escaped := html.EscapeString(strings.ReplaceAll(body, "\r\n", "\n"))
+		return "<div>" + strings.ReplaceAll(escaped, "\n", "<br>\n") + "</div>", text, nil
+	}
+
+	return "", "", fmt.Errorf("%w: %q", ErrUnknownBodyFormat, pl.BodyFormat)
+}
+
+// Whether Plunk would read body as Markdown.
+func startsWithHeading(body string) bool {
+	return strings.HasPrefix(strings.TrimSpace(body), "#")
+}
+
+func renderMarkdown(source string) (string, error) {
+	var b bytes.Buffer
+	if err := markdown.Convert([]byte(source), &b); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,85 @@
+package plunk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kayode0x/plunk/plunktest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreview(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  TransactionalEmailPayload
+		expected string
+	}{
+		{
+			name:     "guessed markdown",
+			payload:  TransactionalEmailPayload{Body: "# Welcome\n\nThanks for **signing up**."},
+			expected: "<h1>Welcome</h1>\n<p>Thanks for <strong>signing up</strong>.</p>\n",
+		},
+		{
+			name:     "guessed html",
+			payload:  TransactionalEmailPayload{Body: "<p>Hello</p>"},
+			expected: "<p>Hello</p>",
+		},
+		{
+			name:     "markdown without a heading",
+			payload:  TransactionalEmailPayload{Body: "Your order:\n\n- 1 × ~~Mug~~ Cup\n- [Track it](https://example.com/track)", BodyFormat: BodyFormatMarkdown},
+			expected: "<p>Your order:</p>\n<ul>\n<li>1 × <del>Mug</del> Cup</li>\n<li><a href=\"https://example.com/track\">Track it</a></li>\n</ul>\n",
+		},
+		{
+			name:     "html starting with #",
+			payload:  TransactionalEmailPayload{Body: "#1 on the waitlist: <b>you</b>", BodyFormat: BodyFormatHTML},
+			expected: "<div>#1 on the waitlist: <b>you</b></div>",
+		},
+		{
+			name:     "text",
+			payload:  TransactionalEmailPayload{Body: "# of seats: 3\r\nTotal < $10", BodyFormat: BodyFormatText},
+			expected: "<div># of seats: 3<br>\nTotal &lt; $10</div>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			preview, err := test.payload.Preview()
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, preview)
+		})
+	}
+
+	_, err := TransactionalEmailPayload{Body: "Hi", BodyFormat: "rtf"}.Preview()
+	assert.True(t, errors.Is(err, ErrUnknownBodyFormat))
+}
+
+func TestSendTransactionalEmailBodyFormat(t *testing.T) {
+	p, server := newTestClient(t)
+
+	_, err := p.SendTransactionalEmailBatch([]TransactionalEmailPayload{
+		{To: []string{"a@example.com"}, Subject: "Subject", Body: "Thanks for **signing up**.", BodyFormat: BodyFormatMarkdown},
+		{To: []string{"b@example.com"}, Subject: "Subject", Body: "#1 on the <a href=\"https://example.com\">waitlist</a>", BodyFormat: BodyFormatHTML},
+		{To: []string{"c@example.com"}, Subject: "Subject", Body: "# of seats: 3", Text: "Seats: 3", BodyFormat: BodyFormatText},
+		{To: []string{"d@example.com"}, Subject: "Subject", Body: "# Left as is"},
+	})
+	assert.Nil(t, err)
+
+	// batches are sent concurrently, so emails are looked up by recipient
+	emails := map[string]plunktest.Email{}
+	for _, email := range server.Emails() {
+		emails[email.To] = email
+	}
+
+	assert.Equal(t, "<p>Thanks for <strong>signing up</strong>.</p>\n", emails["a@example.com"].Body)
+	assert.Equal(t, "Thanks for **signing up**.", emails["a@example.com"].Text)
+	assert.Equal(t, "<div>#1 on the <a href=\"https://example.com\">waitlist</a></div>", emails["b@example.com"].Body)
+	assert.Equal(t, "#1 on the waitlist (https://example.com)", emails["b@example.com"].Text)
+	assert.Equal(t, "<div># of seats: 3</div>", emails["c@example.com"].Body)
+	assert.Equal(t, "Seats: 3", emails["c@example.com"].Text)
+	assert.Equal(t, "# Left as is", emails["d@example.com"].Body)
+	assert.Equal(t, "", emails["d@example.com"].Text)
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{To: []string{"a@example.com"}, Subject: "Subject", Body: "Body", BodyFormat: "rtf"})
+	assert.True(t, errors.Is(err, ErrUnknownBodyFormat))
+}
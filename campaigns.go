@@ -0,0 +1,218 @@
+package plunk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// How Plunk renders an email body: with its own template styling, or as raw HTML.
+type Style string
+
+const (
+	StylePlunk Style = "PLUNK"
+	StyleHTML  Style = "HTML"
+)
+
+type Campaign struct {
+	ID        string  `json:"id"`
+	Subject   string  `json:"subject"`
+	Body      string  `json:"body"`
+	Status    string  `json:"status"`    // DRAFT until the campaign has been sent, then DELIVERED
+	Delivered *string `json:"delivered"` // when the campaign was sent, if it was
+	Style     Style   `json:"style"`
+	ProjectID string  `json:"projectId"`
+	CreatedAt string  `json:"createdAt"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+type CampaignPayload struct {
+	Subject    string   `json:"subject"`
+	Body       string   `json:"body"`
+	Recipients []string `json:"recipients"` // IDs or email addresses of the contacts to send to
+	Style      Style    `json:"style,omitempty"`
+}
+
+type SendCampaignPayload struct {
+	ID    string `json:"id"`
+	Live  bool   `json:"live"`            // When false, a test email is sent to the project members only.
+	Delay int    `json:"delay,omitempty"` // Minutes to wait before sending, to schedule the campaign.
+}
+
+type SendCampaignResponse struct {
+	Success bool `json:"success"`
+}
+
+var (
+	ErrMissingCampaignID      = errors.New("missing campaign id")
+	ErrMissingRecipients      = errors.New("missing recipients")
+	ErrInvalidDelay           = errors.New("delay must not be negative")
+	ErrCouldNotCreateCampaign = errors.New("could not create campaign")
+)
+
+func (c CampaignPayload) validate() error {
+	if c.Subject == "" {
+		return ErrMissingSubject
+	}
+
+	if c.Body == "" {
+		return ErrMissingBody
+	}
+
+	if len(c.Recipients) == 0 {
+		return ErrMissingRecipients
+	}
+
+	return nil
+}
+
+// Creates a draft campaign. Nothing is sent until SendCampaign is called.
+func (p *Plunk) CreateCampaign(payload CampaignPayload) (*Campaign, error) {
+	return p.CreateCampaignContext(context.Background(), payload)
+}
+
+// Like CreateCampaign, but the request is bound to ctx.
+func (p *Plunk) CreateCampaignContext(ctx context.Context, payload CampaignPayload) (*Campaign, error) {
+	if err := payload.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &Campaign{}
+	url := p.url(campaignsEndpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodPost,
+		Body:   payload,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.ID == "" {
+		return nil, ErrCouldNotCreateCampaign
+	}
+
+	p.logInfo("campaign created", "id", result.ID)
+
+	return result, nil
+}
+
+// Replaces the subject, body, recipients and style of a campaign.
+func (p *Plunk) UpdateCampaign(id string, payload CampaignPayload) (*Campaign, error) {
+	return p.UpdateCampaignContext(context.Background(), id, payload)
+}
+
+// Like UpdateCampaign, but the request is bound to ctx.
+func (p *Plunk) UpdateCampaignContext(ctx context.Context, id string, payload CampaignPayload) (*Campaign, error) {
+	if id == "" {
+		return nil, ErrMissingCampaignID
+	}
+
+	if err := payload.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &Campaign{}
+	url := p.url(campaignsEndpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodPut,
+		Body: struct {
+			ID string `json:"id"`
+			CampaignPayload
+		}{id, payload},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("campaign updated", "id", id)
+
+	return result, nil
+}
+
+// Deletes a campaign.
+func (p *Plunk) DeleteCampaign(id string) (*Campaign, error) {
+	return p.DeleteCampaignContext(context.Background(), id)
+}
+
+// Like DeleteCampaign, but the request is bound to ctx.
+func (p *Plunk) DeleteCampaignContext(ctx context.Context, id string) (*Campaign, error) {
+	if id == "" {
+		return nil, ErrMissingCampaignID
+	}
+
+	url := p.url(campaignsEndpoint)
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodDelete,
+		Body:   map[string]string{"id": id},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Campaign{}
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("campaign deleted", "id", id)
+
+	return result, nil
+}
+
+// Sends a campaign to its recipients, or schedules it when payload.Delay is set.
+// Set payload.Live to false to send a test email to the project members first.
+func (p *Plunk) SendCampaign(payload SendCampaignPayload) (*SendCampaignResponse, error) {
+	return p.SendCampaignContext(context.Background(), payload)
+}
+
+// Like SendCampaign, but the request is bound to ctx.
+func (p *Plunk) SendCampaignContext(ctx context.Context, payload SendCampaignPayload) (*SendCampaignResponse, error) {
+	if payload.ID == "" {
+		return nil, ErrMissingCampaignID
+	}
+
+	if payload.Delay < 0 {
+		return nil, ErrInvalidDelay
+	}
+
+	result := &SendCampaignResponse{}
+	url := p.url(campaignsSendEndpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodPost,
+		Body:   payload,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("campaign sent", "id", payload.ID, "live", payload.Live, "delay", payload.Delay)
+
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package plunk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCampaign(t *testing.T) {
+	p, server := newTestClient(t)
+
+	campaign, err := p.CreateCampaign(CampaignPayload{
+		Subject:    "Spring sale",
+		Body:       "# 20% off everything",
+		Recipients: []string{"a@example.com", "b@example.com"},
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, campaign.ID)
+	assert.Equal(t, "Spring sale", campaign.Subject)
+	assert.Equal(t, "DRAFT", campaign.Status)
+	assert.Equal(t, StylePlunk, campaign.Style)
+	assert.Nil(t, campaign.Delivered)
+
+	campaigns := server.Campaigns()
+	assert.Len(t, campaigns, 1)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, campaigns[0].Recipients)
+}
+
+func TestCreateCampaignWithInvalidPayload(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	testCases := []struct {
+		payload CampaignPayload
+		err     error
+	}{
+		{
+			payload: CampaignPayload{Body: "Body", Recipients: []string{"a@example.com"}},
+			err:     ErrMissingSubject,
+		},
+		{
+			payload: CampaignPayload{Subject: "Subject", Recipients: []string{"a@example.com"}},
+			err:     ErrMissingBody,
+		},
+		{
+			payload: CampaignPayload{Subject: "Subject", Body: "Body"},
+			err:     ErrMissingRecipients,
+		},
+	}
+
+	for _, tc := range testCases {
+		campaign, err := p.CreateCampaign(tc.payload)
+		assert.Equal(t, tc.err, err)
+		assert.Nil(t, campaign)
+	}
+}
+
+func TestUpdateCampaign(t *testing.T) {
+	p, server := newTestClient(t)
+
+	campaign, err := p.CreateCampaign(CampaignPayload{
+		Subject:    "Spring sale",
+		Body:       "# 20% off everything",
+		Recipients: []string{"a@example.com"},
+	})
+	assert.Nil(t, err)
+
+	updated, err := p.UpdateCampaign(campaign.ID, CampaignPayload{
+		Subject:    "Summer sale",
+		Body:       "<h1>30% off everything</h1>",
+		Recipients: []string{"a@example.com", "c@example.com"},
+		Style:      StyleHTML,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, campaign.ID, updated.ID)
+	assert.Equal(t, "Summer sale", updated.Subject)
+	assert.Equal(t, StyleHTML, updated.Style)
+	assert.Equal(t, []string{"a@example.com", "c@example.com"}, server.Campaigns()[0].Recipients)
+
+	_, err = p.UpdateCampaign("", CampaignPayload{})
+	assert.Equal(t, ErrMissingCampaignID, err)
+
+	_, err = p.UpdateCampaign("unknown", CampaignPayload{Subject: "s", Body: "b", Recipients: []string{"a@example.com"}})
+	assert.Equal(t, "Plunk Error (Code: 404, Error: Not Found, Message: That campaign was not found)", err.Error())
+}
+
+func TestDeleteCampaign(t *testing.T) {
+	p, server := newTestClient(t)
+
+	campaign, err := p.CreateCampaign(CampaignPayload{
+		Subject:    "Spring sale",
+		Body:       "# 20% off everything",
+		Recipients: []string{"a@example.com"},
+	})
+	assert.Nil(t, err)
+
+	deleted, err := p.DeleteCampaign(campaign.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, campaign.ID, deleted.ID)
+	assert.Empty(t, server.Campaigns())
+
+	_, err = p.DeleteCampaign(campaign.ID)
+	assert.NotNil(t, err)
+
+	_, err = p.DeleteCampaign("")
+	assert.Equal(t, ErrMissingCampaignID, err)
+}
+
+func TestSendCampaign(t *testing.T) {
+	p, server := newTestClient(t)
+
+	campaign, err := p.CreateCampaign(CampaignPayload{
+		Subject:    "Spring sale",
+		Body:       "# 20% off everything",
+		Recipients: []string{"a@example.com", "b@example.com"},
+	})
+	assert.Nil(t, err)
+
+	// a test send leaves the campaign as a draft
+	res, err := p.SendCampaign(SendCampaignPayload{ID: campaign.ID})
+	assert.Nil(t, err)
+	assert.True(t, res.Success)
+	assert.Equal(t, "DRAFT", server.Campaigns()[0].Status)
+
+	// scheduled for an hour from now
+	res, err = p.SendCampaign(SendCampaignPayload{ID: campaign.ID, Live: true, Delay: 60})
+	assert.Nil(t, err)
+	assert.True(t, res.Success)
+	assert.Equal(t, "DELIVERED", server.Campaigns()[0].Status)
+
+	sent := server.SentCampaigns()
+	assert.Len(t, sent, 2)
+	assert.False(t, sent[0].Live)
+	assert.True(t, sent[1].Live)
+	assert.Equal(t, 60, sent[1].Delay)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, sent[1].Recipients)
+
+	// a campaign can only go out once
+	_, err = p.SendCampaign(SendCampaignPayload{ID: campaign.ID, Live: true})
+	assert.NotNil(t, err)
+
+	_, err = p.SendCampaign(SendCampaignPayload{})
+	assert.Equal(t, ErrMissingCampaignID, err)
+
+	_, err = p.SendCampaign(SendCampaignPayload{ID: campaign.ID, Delay: -1})
+	assert.Equal(t, ErrInvalidDelay, err)
+}
@@ -0,0 +1,46 @@
+package plunk
+
+import "sync"
+
+// The number of requests a bulk operation runs at once unless Config.Concurrency is set.
+const defaultConcurrency = 10
+
+func (p *Plunk) concurrency() int {
+	if p.Concurrency < 1 {
+		return defaultConcurrency
+	}
+
+	return p.Concurrency
+}
+
+// Calls fn once for every index in [0, n) from a fixed pool of workers and
+// waits for all of them to return. Callers collect results by writing to the
+// i-th element of a pre-sized slice, so no two goroutines share any state.
+func (p *Plunk) forEach(n int, fn func(i int)) {
+	workers := p.concurrency()
+	if workers > n {
+		workers = n
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+
+	close(next)
+	wg.Wait()
+}
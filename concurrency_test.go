@@ -0,0 +1,50 @@
+package plunk
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEach(t *testing.T) {
+	p, err := New("test-api-key", &Config{Concurrency: 4})
+	assert.Nil(t, err)
+
+	var running, peak int32
+	seen := make([]int, 100)
+	p.forEach(len(seen), func(i int) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			max := atomic.LoadInt32(&peak)
+			if n <= max || atomic.CompareAndSwapInt32(&peak, max, n) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+		seen[i]++
+	})
+
+	for i, n := range seen {
+		assert.Equal(t, 1, n, "index %d", i)
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(4))
+
+	// nothing to do
+	p.forEach(0, func(i int) {
+		t.Error("fn should not be called")
+	})
+}
+
+func TestConcurrencyDefault(t *testing.T) {
+	p, err := New("test-api-key", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, defaultConcurrency, p.concurrency())
+
+	p.Concurrency = 0
+	assert.Equal(t, defaultConcurrency, p.concurrency())
+}
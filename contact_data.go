@@ -0,0 +1,338 @@
+package plunk
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TypedContact is a contact whose data is decoded into a T instead of a map.
+type TypedContact[T any] struct {
+	ID         string
+	Email      string
+	Subscribed bool
+	Data       T
+}
+
+var (
+	// Returned, wrapped with the offending type or field, when a type cannot
+	// be used as contact data.
+	ErrInvalidDataType = errors.New("invalid contact data type")
+
+	// Returned by PatchContactData when the data kept changing after the
+	// patch was written. The stored data may or may not include the patch.
+	ErrConcurrentModification = errors.New("contact data was modified concurrently")
+)
+
+// Number of times PatchContactData writes the patch and reads it back before
+// giving up with ErrConcurrentModification.
+const maxPatchAttempts = 5
+
+// Gets a contact and decodes its data into a T, honoring its json struct tags.
+// A contact without data gets the zero value of T.
+func GetContactAs[T any](p *Plunk, id string) (*TypedContact[T], error) {
+	return GetContactAsContext[T](context.Background(), p, id)
+}
+
+// Like GetContactAs, but the request is bound to ctx.
+func GetContactAsContext[T any](ctx context.Context, p *Plunk, id string) (*TypedContact[T], error) {
+	if err := checkDataType(reflect.TypeOf((*T)(nil)).Elem()); err != nil {
+		return nil, err
+	}
+
+	contact, err := p.GetContactContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return typedContact[T](contact)
+}
+
+// Replaces the data of a contact with data, keeping its email and
+// subscription status.
+func UpdateContactData[T any](p *Plunk, id string, data T) (*TypedContact[T], error) {
+	return UpdateContactDataContext(context.Background(), p, id, data)
+}
+
+// Like UpdateContactData, but the requests are bound to ctx.
+func UpdateContactDataContext[T any](ctx context.Context, p *Plunk, id string, data T) (*TypedContact[T], error) {
+	if err := checkDataType(reflect.TypeOf((*T)(nil)).Elem()); err != nil {
+		return nil, err
+	}
+
+	m, err := dataToMap(data)
+	if err != nil {
+		return nil, err
+	}
+
+	contact, err := p.GetContactContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	contact.Data = m
+
+	contact, err = p.UpdateContactContext(ctx, contact)
+	if err != nil {
+		return nil, err
+	}
+
+	return typedContact[T](contact)
+}
+
+// Merges patch into the stored data of a contact, leaving other keys as they
+// are. Nested maps are merged recursively and keys set to nil are deleted, as
+// in a JSON merge patch (RFC 7396).
+//
+// The API has no conditional writes, so the data is read back after writing;
+// when it is no longer what was written, another client wrote in between, and
+// the patch is merged into its data and written again. A write by another
+// client that lands between the read and the write is still overwritten,
+// unless that client reads its write back too, e.g. with PatchContactData.
+func (p *Plunk) PatchContactData(id string, patch map[string]interface{}) (*Contact, error) {
+	return p.PatchContactDataContext(context.Background(), id, patch)
+}
+
+// Like PatchContactData, but the requests are bound to ctx.
+func (p *Plunk) PatchContactDataContext(ctx context.Context, id string, patch map[string]interface{}) (*Contact, error) {
+	if id == "" {
+		return nil, ErrMissingContactID
+	}
+
+	contact, err := p.GetContactContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		// normalized, so that struct values and large integers hash the same
+		// as they do when read back
+		contact.Data, err = dataToMap(mergePatch(contact.Data, patch))
+		if err != nil {
+			return nil, err
+		}
+
+		written, err := dataHash(contact.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.UpdateContactContext(ctx, contact); err != nil {
+			return nil, err
+		}
+
+		stored, err := p.GetContactContext(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		hash, err := storedDataHash(stored)
+		if err != nil {
+			return nil, err
+		}
+
+		if hash == written {
+			p.logInfo("contact data patched", "id", id)
+			return stored, nil
+		}
+
+		if attempt >= maxPatchAttempts {
+			return nil, ErrConcurrentModification
+		}
+
+		p.logInfo("contact data changed, merging patch again", "id", id, "attempt", attempt)
+		contact = stored
+	}
+}
+
+// Returns a copy of data with patch merged in. Neither map is modified.
+func mergePatch(data, patch map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(data)+len(patch))
+	for k, v := range data {
+		result[k] = v
+	}
+
+	for k, v := range patch {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+
+		if p, ok := v.(map[string]interface{}); ok {
+			d, _ := result[k].(map[string]interface{})
+			result[k] = mergePatch(d, p)
+			continue
+		}
+
+		result[k] = v
+	}
+
+	return result
+}
+
+// Hashes the JSON encoding of data, which lists map keys in sorted order.
+func dataHash(data map[string]interface{}) ([sha256.Size]byte, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	return sha256.Sum256(b), nil
+}
+
+// Hashes the data of c as stored, rather than its parsed Data, in which large
+// integers are rounded to float64.
+func storedDataHash(c *Contact) ([sha256.Size]byte, error) {
+	var data map[string]interface{}
+	if c.DataString != nil && *c.DataString != "" {
+		var err error
+		if data, err = dataToMap(json.RawMessage(*c.DataString)); err != nil {
+			return [sha256.Size]byte{}, err
+		}
+	}
+
+	return dataHash(data)
+}
+
+func typedContact[T any](c *Contact) (*TypedContact[T], error) {
+	result := &TypedContact[T]{
+		ID:         c.ID,
+		Email:      c.Email,
+		Subscribed: c.Subscribed,
+	}
+
+	if c.DataString != nil && *c.DataString != "" {
+		if err := json.Unmarshal([]byte(*c.DataString), &result.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Converts data into the map UpdateContact sends. Numbers are kept as
+// json.Number so that large integers survive the round trip.
+func dataToMap(data interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: %s is not a JSON object", ErrInvalidDataType, reflect.TypeOf(data))
+	}
+
+	return m, nil
+}
+
+var (
+	checkedDataTypes sync.Map // reflect.Type -> error
+
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Checks that t encodes to a JSON object and that every field it encodes can
+// be marshalled. Results are cached per type.
+func checkDataType(t reflect.Type) error {
+	if err, ok := checkedDataTypes.Load(t); ok {
+		if err == nil {
+			return nil
+		}
+
+		return err.(error)
+	}
+
+	err := checkDataObject(t)
+	checkedDataTypes.Store(t, err)
+
+	return err
+}
+
+func checkDataObject(t reflect.Type) error {
+	elem := t
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+
+	switch {
+	case implementsMarshaler(elem):
+	case elem.Kind() == reflect.Struct:
+	case elem.Kind() == reflect.Map && elem.Key().Kind() == reflect.String:
+	default:
+		return fmt.Errorf("%w: %s does not encode to a JSON object", ErrInvalidDataType, t)
+	}
+
+	return checkJSONType(t, t.String(), map[reflect.Type]bool{})
+}
+
+// Walks t the way encoding/json would, reporting the first field whose type
+// json.Marshal rejects.
+func checkJSONType(t reflect.Type, path string, seen map[reflect.Type]bool) error {
+	if seen[t] || implementsMarshaler(t) {
+		return nil
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return fmt.Errorf("%w: %s has type %s, which cannot be encoded as JSON", ErrInvalidDataType, path, t)
+
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return checkJSONType(t.Elem(), path, seen)
+
+	case reflect.Map:
+		key := t.Key()
+		switch key.Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !key.Implements(textMarshalerType) {
+				return fmt.Errorf("%w: %s has map key type %s, which cannot be encoded as JSON", ErrInvalidDataType, path, key)
+			}
+		}
+
+		return checkJSONType(t.Elem(), path, seen)
+
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+
+			name := field.Name
+			if tag := field.Tag.Get("json"); tag != "" {
+				if tag == "-" {
+					continue
+				}
+
+				if n, _, _ := strings.Cut(tag, ","); n != "" {
+					name = n
+				}
+			}
+
+			if err := checkJSONType(field.Type, path+"."+name, seen); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func implementsMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
@@ -0,0 +1,312 @@
+package plunk
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kayode0x/plunk/plunktest"
+	"github.com/stretchr/testify/assert"
+)
+
+type testProfile struct {
+	FirstName string    `json:"first_name"`
+	Plan      string    `json:"plan,omitempty"`
+	Seats     int64     `json:"seats"`
+	Tags      []string  `json:"tags"`
+	Joined    time.Time `json:"joined"`
+	internal  string
+}
+
+func TestGetContactAs(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	contact, err := p.CreateContact(CreateContactPayload{
+		Email: testEmail,
+		Data: map[string]interface{}{
+			"first_name": "John",
+			"seats":      3,
+			"tags":       []string{"beta"},
+		},
+	})
+	assert.Nil(t, err)
+
+	typed, err := GetContactAs[testProfile](p, contact.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, contact.ID, typed.ID)
+	assert.Equal(t, testEmail, typed.Email)
+	assert.Equal(t, "John", typed.Data.FirstName)
+	assert.Equal(t, int64(3), typed.Data.Seats)
+	assert.Equal(t, []string{"beta"}, typed.Data.Tags)
+
+	// data that does not fit T is an error
+	untyped, err := GetContactAs[map[string]string](p, contact.ID)
+	assert.NotNil(t, err)
+	assert.Nil(t, untyped)
+
+	// a contact without data gets the zero value
+	empty, err := p.CreateContact(CreateContactPayload{Email: "empty@example.com"})
+	assert.Nil(t, err)
+
+	typed, err = GetContactAs[testProfile](p, empty.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, testProfile{}, typed.Data)
+
+	_, err = GetContactAs[testProfile](p, "")
+	assert.Equal(t, ErrMissingContactID, err)
+}
+
+func TestUpdateContactData(t *testing.T) {
+	p, srv := newTestClient(t)
+
+	contact, err := p.CreateContact(CreateContactPayload{
+		Email:      testEmail,
+		Subscribed: true,
+		Data:       map[string]interface{}{"stale": true},
+	})
+	assert.Nil(t, err)
+
+	joined := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	typed, err := UpdateContactData(p, contact.ID, testProfile{
+		FirstName: "Jane",
+		Seats:     1 << 60,
+		Joined:    joined,
+		internal:  "not sent",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Jane", typed.Data.FirstName)
+	assert.Equal(t, int64(1<<60), typed.Data.Seats)
+	assert.True(t, joined.Equal(typed.Data.Joined))
+	assert.True(t, typed.Subscribed)
+
+	stored, _ := srv.Contact(testEmail)
+	assert.True(t, stored.Subscribed)
+	assert.Equal(t, "Jane", stored.Data["first_name"])
+	assert.NotContains(t, stored.Data, "plan")
+	assert.NotContains(t, stored.Data, "stale")
+	assert.NotContains(t, stored.Data, "internal")
+
+	_, err = UpdateContactData(p, "unknown", testProfile{})
+	assert.True(t, errors.Is(err, ErrContactNotFound))
+}
+
+func TestContactDataTypeValidation(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	contact, err := p.CreateContact(CreateContactPayload{Email: testEmail})
+	assert.Nil(t, err)
+
+	type withChannel struct {
+		Name    string        `json:"name"`
+		Updates chan struct{} `json:"updates"`
+	}
+
+	type withIgnoredFunc struct {
+		Name     string       `json:"name"`
+		Callback func() error `json:"-"`
+	}
+
+	type nested struct {
+		Inner struct {
+			Values map[string]complex128
+		} `json:"inner"`
+	}
+
+	_, err = UpdateContactData(p, contact.ID, withChannel{})
+	assert.True(t, errors.Is(err, ErrInvalidDataType))
+	assert.Contains(t, err.Error(), "withChannel.updates")
+
+	_, err = UpdateContactData(p, contact.ID, nested{})
+	assert.True(t, errors.Is(err, ErrInvalidDataType))
+	assert.Contains(t, err.Error(), "nested.inner.Values")
+
+	_, err = UpdateContactData(p, contact.ID, []string{"not", "an", "object"})
+	assert.True(t, errors.Is(err, ErrInvalidDataType))
+
+	_, err = GetContactAs[int](p, contact.ID)
+	assert.True(t, errors.Is(err, ErrInvalidDataType))
+
+	_, err = UpdateContactData(p, contact.ID, withIgnoredFunc{Name: "ok"})
+	assert.Nil(t, err)
+
+	_, err = UpdateContactData(p, contact.ID, map[string]interface{}{"ok": true})
+	assert.Nil(t, err)
+
+	_, err = UpdateContactData(p, contact.ID, &testProfile{FirstName: "pointer"})
+	assert.Nil(t, err)
+}
+
+func TestPatchContactData(t *testing.T) {
+	p, srv := newTestClient(t)
+
+	contact, err := p.CreateContact(CreateContactPayload{
+		Email:      testEmail,
+		Subscribed: true,
+		Data: map[string]interface{}{
+			"plan":    "free",
+			"company": "Acme",
+			"address": map[string]interface{}{"city": "Lagos", "zip": "100001"},
+		},
+	})
+	assert.Nil(t, err)
+
+	patched, err := p.PatchContactData(contact.ID, map[string]interface{}{
+		"plan":    "pro",
+		"company": nil,
+		"address": map[string]interface{}{"zip": nil, "country": "NG"},
+	})
+	assert.Nil(t, err)
+	assert.True(t, patched.Subscribed)
+
+	expected := map[string]interface{}{
+		"plan":    "pro",
+		"address": map[string]interface{}{"city": "Lagos", "country": "NG"},
+	}
+	assert.Equal(t, expected, patched.Data)
+
+	stored, _ := srv.Contact(testEmail)
+	assert.Equal(t, expected, stored.Data)
+	assert.True(t, stored.Subscribed)
+
+	_, err = p.PatchContactData("", nil)
+	assert.Equal(t, ErrMissingContactID, err)
+
+	_, err = p.PatchContactData("unknown", map[string]interface{}{"plan": "pro"})
+	assert.True(t, errors.Is(err, ErrContactNotFound))
+}
+
+func TestPatchContactDataValues(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	contact, err := p.CreateContact(CreateContactPayload{Email: testEmail})
+	assert.Nil(t, err)
+
+	type address struct {
+		Street string `json:"street"`
+		City   string `json:"city"`
+	}
+
+	// structs are read back as maps with sorted keys, and large integers as
+	// rounded floats, but neither is mistaken for a concurrent write
+	patched, err := p.PatchContactData(contact.ID, map[string]interface{}{
+		"address": address{Street: "1 Marina", City: "Lagos"},
+		"big":     int64(9007199254740993),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"street": "1 Marina", "city": "Lagos"}, patched.Data["address"])
+	assert.Contains(t, *patched.DataString, "9007199254740993")
+}
+
+func TestMergePatch(t *testing.T) {
+	data := map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{"c": 2},
+	}
+
+	merged := mergePatch(data, map[string]interface{}{
+		"a": nil,
+		"b": map[string]interface{}{"d": 3},
+		"e": map[string]interface{}{"f": nil, "g": 4},
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"b": map[string]interface{}{"c": 2, "d": 3},
+		"e": map[string]interface{}{"g": 4},
+	}, merged)
+
+	// neither input is modified
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2}}, data)
+	assert.Equal(t, map[string]interface{}{"x": true}, mergePatch(nil, map[string]interface{}{"x": true}))
+}
+
+// Returns a client whose first interfere writes to the contact are each
+// followed by a write from another client, landing before the first client
+// can read its write back.
+func newInterferingClient(t *testing.T, interfere int) (*Plunk, *plunktest.Server, string) {
+	t.Helper()
+
+	srv := plunktest.NewServer()
+	t.Cleanup(srv.Close)
+
+	other, err := New(srv.ApiKey, &Config{BaseUrl: srv.BaseUrl})
+	assert.Nil(t, err)
+
+	contact, err := other.CreateContact(CreateContactPayload{
+		Email: testEmail,
+		Data:  map[string]interface{}{"writer": 0},
+	})
+	assert.Nil(t, err)
+
+	var writes int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			srv.ServeHTTP(w, r)
+			return
+		}
+
+		// the response is held back until the other write is done
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, r)
+
+		if n := atomic.AddInt32(&writes, 1); int(n) <= interfere {
+			_, err := other.UpdateContact(contactWithData(t, other, contact.ID, map[string]interface{}{"writer": n}))
+			assert.Nil(t, err)
+		}
+
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	}))
+	t.Cleanup(proxy.Close)
+
+	p, err := New(srv.ApiKey, &Config{BaseUrl: proxy.URL})
+	assert.Nil(t, err)
+
+	return p, srv, contact.ID
+}
+
+// Gets a contact and replaces its data, the way a client unaware of
+// PatchContactData would.
+func contactWithData(t *testing.T, p *Plunk, id string, data map[string]interface{}) *Contact {
+	t.Helper()
+
+	contact, err := p.GetContact(id)
+	assert.Nil(t, err)
+
+	contact.Data = data
+
+	return contact
+}
+
+func TestPatchContactDataConcurrentWrite(t *testing.T) {
+	p, srv, id := newInterferingClient(t, 1)
+
+	patched, err := p.PatchContactData(id, map[string]interface{}{"plan": "pro"})
+	assert.Nil(t, err)
+
+	// the other client's write replaced the patch, which was merged into it
+	// and written again
+	assert.Equal(t, "pro", patched.Data["plan"])
+	assert.Equal(t, float64(1), patched.Data["writer"])
+
+	stored, _ := srv.Contact(testEmail)
+	assert.Equal(t, "pro", stored.Data["plan"])
+	assert.Equal(t, float64(1), stored.Data["writer"])
+}
+
+func TestPatchContactDataConcurrentModification(t *testing.T) {
+	p, srv, id := newInterferingClient(t, maxPatchAttempts)
+
+	patched, err := p.PatchContactData(id, map[string]interface{}{"plan": "pro"})
+	assert.Equal(t, ErrConcurrentModification, err)
+	assert.Nil(t, patched)
+
+	stored, _ := srv.Contact(testEmail)
+	assert.NotContains(t, stored.Data, "plan")
+	assert.Equal(t, float64(maxPatchAttempts), stored.Data["writer"])
+}
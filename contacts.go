@@ -1,10 +1,11 @@
 package plunk
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
-	"sync"
+	"strings"
 )
 
 type Contact struct {
@@ -25,6 +26,14 @@ type CreateContactPayload struct {
 	Data       map[string]interface{} `json:"data"`
 }
 
+// UpsertContactPayload is the desired state of a contact. Fields left nil
+// keep their value on an existing contact.
+type UpsertContactPayload struct {
+	Email      string
+	Subscribed *bool // new contacts are created unsubscribed when nil
+	Data       map[string]interface{}
+}
+
 var (
 	ErrMissingContactID           = errors.New("missing contact id")
 	ErrCouldNotCreateContact      = errors.New("could not create contact")
@@ -35,6 +44,11 @@ var (
 	ErrCouldNotUnsubscribeContact = errors.New("could not unsubscribe contact")
 	ErrCouldNotDeleteContact      = errors.New("could not delete contact")
 	ErrCouldNotUpdateContact      = errors.New("could not update contact")
+
+	// Matched by errors.Is when the API refuses to create a contact whose email is taken.
+	ErrContactAlreadyExists = errors.New("contact already exists")
+	// Matched by errors.Is when the API has no contact with the requested ID or email.
+	ErrContactNotFound = errors.New("contact not found")
 )
 
 func (r *Contact) ParseData() error {
@@ -50,6 +64,11 @@ func (r *Contact) ParseData() error {
 
 // Gets the details of a specific contact.
 func (p *Plunk) GetContact(id string) (*Contact, error) {
+	return p.GetContactContext(context.Background(), id)
+}
+
+// Like GetContact, but the request is bound to ctx.
+func (p *Plunk) GetContactContext(ctx context.Context, id string) (*Contact, error) {
 	if id == "" {
 		return nil, ErrMissingContactID
 	}
@@ -58,7 +77,7 @@ func (p *Plunk) GetContact(id string) (*Contact, error) {
 	endpoint := fmt.Sprintf("%s/%s", contactsEndpoint, id)
 	url := p.url(endpoint)
 
-	resp, err := p.sendRequest(SendConfig{
+	resp, err := p.sendRequest(ctx, SendConfig{
 		Url:    url,
 		Method: http.MethodGet,
 	})
@@ -77,70 +96,80 @@ func (p *Plunk) GetContact(id string) (*Contact, error) {
 	}
 
 	result.ParseData()
-	p.logInfo(fmt.Sprintf("Contact retrieved: %s ", id))
+	p.logInfo("contact retrieved", "id", id)
 
 	return result, nil
 }
 
 // Get a list of all contacts in your Plunk account.
+// Use ListContacts to stream large lists instead of loading them at once.
 func (p *Plunk) GetContacts() ([]*Contact, error) {
+	return p.GetContactsContext(context.Background())
+}
+
+// Like GetContacts, but the request is bound to ctx.
+func (p *Plunk) GetContactsContext(ctx context.Context) ([]*Contact, error) {
 	result := []*Contact{}
-	url := p.url(contactsEndpoint)
 
-	resp, err := p.sendRequest(SendConfig{
-		Url:    url,
-		Method: http.MethodGet,
-	})
+	it := p.ListContactsContext(ctx, ListOptions{})
+	defer it.Close()
 
-	if err != nil {
-		p.logError(fmt.Sprintf("Could not send request: %s", err.Error()))
-		return nil, err
+	for it.Next() {
+		result = append(result, it.Contact())
 	}
 
-	err = decodeResponse(resp, &result)
-	if err != nil {
-		p.logError(fmt.Sprintf("Could not decode response: %s", err.Error()))
+	if err := it.Err(); err != nil {
+		p.logError("could not get contacts", "error", err)
 		return nil, err
 	}
 
-	sem := make(chan bool, 10)
-	var wg sync.WaitGroup
+	p.logInfo("contacts retrieved", "count", len(result))
 
-	for _, contact := range result {
-		wg.Add(1)
-		sem <- true
+	return result, nil
+}
 
-		go func(contact *Contact) {
-			defer wg.Done()
-			defer func() { <-sem }()
+// Gets the contact with the given email address. The API can only look
+// contacts up by ID, so this walks the contact list until it finds a match.
+// Returns ErrContactNotFound when there is none.
+func (p *Plunk) GetContactByEmail(email string) (*Contact, error) {
+	return p.GetContactByEmailContext(context.Background(), email)
+}
 
-			err := contact.ParseData()
-			if err != nil {
-				p.logError(fmt.Sprintf("Could not parse data: %s", err.Error()))
-			}
-		}(contact)
+// Like GetContactByEmail, but the requests are bound to ctx.
+func (p *Plunk) GetContactByEmailContext(ctx context.Context, email string) (*Contact, error) {
+	if email == "" {
+		return nil, ErrMissingEmail
 	}
 
-	wg.Wait()
+	it := p.ListContactsContext(ctx, ListOptions{})
+	defer it.Close()
 
-	close(sem)
-
-	if result == nil {
-		return nil, ErrCouldNotGetContacts
+	for it.Next() {
+		if strings.EqualFold(it.Contact().Email, email) {
+			p.logInfo("contact retrieved", "id", it.Contact().ID)
+			return it.Contact(), nil
+		}
 	}
 
-	p.logInfo(fmt.Sprintf("Retrieved %d contacts", len(result)))
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
 
-	return result, nil
+	return nil, ErrContactNotFound
 }
 
 // Gets the total number of contacts in your Plunk account.
 // Useful for displaying the number of contacts in a dashboard, landing page or other marketing material.
 func (p *Plunk) GetContactsCount() (int, error) {
+	return p.GetContactsCountContext(context.Background())
+}
+
+// Like GetContactsCount, but the request is bound to ctx.
+func (p *Plunk) GetContactsCountContext(ctx context.Context) (int, error) {
 	result := &ContactsCountResponse{}
 	url := p.url(contactsCountEndpoint)
 
-	resp, err := p.sendRequest(SendConfig{
+	resp, err := p.sendRequest(ctx, SendConfig{
 		Url:    url,
 		Method: http.MethodGet,
 	})
@@ -158,17 +187,27 @@ func (p *Plunk) GetContactsCount() (int, error) {
 		return 0, ErrCouldNotGetCount
 	}
 
-	p.logInfo(fmt.Sprintf("Retrieved %d contacts", result))
+	p.logInfo("contacts counted", "count", result.Count)
 
 	return result.Count, nil
 }
 
 // Used to create a new contact in your Plunk project without triggering an event
+// The error matches ErrContactAlreadyExists when the email is already taken.
 func (p *Plunk) CreateContact(payload CreateContactPayload) (*Contact, error) {
+	return p.CreateContactContext(context.Background(), payload)
+}
+
+// Like CreateContact, but the request is bound to ctx.
+func (p *Plunk) CreateContactContext(ctx context.Context, payload CreateContactPayload) (*Contact, error) {
+	if err := p.validateEmail("Email", 0, &payload.Email); err != nil {
+		return nil, err
+	}
+
 	result := &Contact{}
 	url := p.url(contactsEndpoint)
 
-	resp, err := p.sendRequest(SendConfig{
+	resp, err := p.sendRequest(ctx, SendConfig{
 		Url:    url,
 		Method: http.MethodPost,
 		Body:   payload,
@@ -187,13 +226,68 @@ func (p *Plunk) CreateContact(payload CreateContactPayload) (*Contact, error) {
 		return nil, errors.New("")
 	}
 
-	p.logInfo(fmt.Sprintf("Contact created: %s ", payload.Email))
+	p.logInfo("contact created", "id", result.ID)
 
 	return result, nil
 }
 
+// Creates the contact, or updates the contact with the same email when it
+// already exists. An existing contact keeps its subscription status unless
+// the payload's Subscribed is set, and its Data unless the payload's Data is.
+// Reports whether the contact was created.
+func (p *Plunk) UpsertContact(payload UpsertContactPayload) (*Contact, bool, error) {
+	return p.UpsertContactContext(context.Background(), payload)
+}
+
+// Like UpsertContact, but the requests are bound to ctx.
+func (p *Plunk) UpsertContactContext(ctx context.Context, payload UpsertContactPayload) (*Contact, bool, error) {
+	if payload.Email == "" {
+		return nil, false, ErrMissingEmail
+	}
+
+	contact, err := p.CreateContactContext(ctx, CreateContactPayload{
+		Email:      payload.Email,
+		Subscribed: payload.Subscribed != nil && *payload.Subscribed,
+		Data:       payload.Data,
+	})
+	if err == nil {
+		contact.ParseData()
+		return contact, true, nil
+	}
+
+	if !errors.Is(err, ErrContactAlreadyExists) {
+		return nil, false, err
+	}
+
+	existing, err := p.GetContactByEmailContext(ctx, payload.Email)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if payload.Subscribed != nil {
+		existing.Subscribed = *payload.Subscribed
+	}
+	if payload.Data != nil {
+		existing.Data = payload.Data
+	}
+
+	contact, err = p.UpdateContactContext(ctx, existing)
+	if err != nil {
+		return nil, false, err
+	}
+
+	contact.ParseData()
+
+	return contact, false, nil
+}
+
 // Update a contact in your Plunk project.
 func (p *Plunk) UpdateContact(c *Contact) (*Contact, error) {
+	return p.UpdateContactContext(context.Background(), c)
+}
+
+// Like UpdateContact, but the request is bound to ctx.
+func (p *Plunk) UpdateContactContext(ctx context.Context, c *Contact) (*Contact, error) {
 	if c.ID == "" {
 		return nil, ErrMissingContactID
 	}
@@ -212,7 +306,7 @@ func (p *Plunk) UpdateContact(c *Contact) (*Contact, error) {
 		Subscribed: c.Subscribed,
 	}
 
-	resp, err := p.sendRequest(SendConfig{
+	resp, err := p.sendRequest(ctx, SendConfig{
 		Url:    url,
 		Body:   payload,
 		Method: http.MethodPut,
@@ -231,19 +325,24 @@ func (p *Plunk) UpdateContact(c *Contact) (*Contact, error) {
 		return nil, ErrCouldNotUpdateContact
 	}
 
-	p.logInfo(fmt.Sprintf("Contact updated: %s ", c.ID))
+	p.logInfo("contact updated", "id", c.ID)
 
 	return result, nil
 }
 
 // Delete a contact from your Plunk project.
 func (p *Plunk) DeleteContact(id string) (*Contact, error) {
+	return p.DeleteContactContext(context.Background(), id)
+}
+
+// Like DeleteContact, but the request is bound to ctx.
+func (p *Plunk) DeleteContactContext(ctx context.Context, id string) (*Contact, error) {
 	if id == "" {
 		return nil, ErrMissingContactID
 	}
 
 	url := p.url(contactsEndpoint)
-	resp, err := p.sendRequest(SendConfig{
+	resp, err := p.sendRequest(ctx, SendConfig{
 		Url:    url,
 		Method: http.MethodDelete,
 		Body:   map[string]string{"id": id},
@@ -263,22 +362,34 @@ func (p *Plunk) DeleteContact(id string) (*Contact, error) {
 		return nil, ErrCouldNotDeleteContact
 	}
 
-	p.logInfo(fmt.Sprintf("Contact deleted: %s ", id))
+	p.logInfo("contact deleted", "id", id)
 
 	return result, nil
 }
 
-// Updates a contact's subscription status to subscribed.
+// Updates a contact's subscription status to subscribed. Plunk returns the
+// contact as it was before the update.
 func (p *Plunk) SubscribeContact(id string) (*Contact, error) {
-	return p.subOrUnsubscribeContact(id, true)
+	return p.subOrUnsubscribeContact(context.Background(), id, true)
 }
 
-// Updates a contact's subscription status to unsubscribed.
+// Like SubscribeContact, but the request is bound to ctx.
+func (p *Plunk) SubscribeContactContext(ctx context.Context, id string) (*Contact, error) {
+	return p.subOrUnsubscribeContact(ctx, id, true)
+}
+
+// Updates a contact's subscription status to unsubscribed. Plunk returns the
+// contact as it was before the update.
 func (p *Plunk) UnsubscribeContact(id string) (*Contact, error) {
-	return p.subOrUnsubscribeContact(id, false)
+	return p.subOrUnsubscribeContact(context.Background(), id, false)
+}
+
+// Like UnsubscribeContact, but the request is bound to ctx.
+func (p *Plunk) UnsubscribeContactContext(ctx context.Context, id string) (*Contact, error) {
+	return p.subOrUnsubscribeContact(ctx, id, false)
 }
 
-func (p *Plunk) subOrUnsubscribeContact(id string, subscribe bool) (*Contact, error) {
+func (p *Plunk) subOrUnsubscribeContact(ctx context.Context, id string, subscribe bool) (*Contact, error) {
 	if id == "" {
 		return nil, ErrMissingContactID
 	}
@@ -290,7 +401,7 @@ func (p *Plunk) subOrUnsubscribeContact(id string, subscribe bool) (*Contact, er
 	}
 
 	url := p.url(endpoint)
-	resp, err := p.sendRequest(SendConfig{
+	resp, err := p.sendRequest(ctx, SendConfig{
 		Url:    url,
 		Method: http.MethodPost,
 		Body:   map[string]string{"id": id},
@@ -313,7 +424,7 @@ func (p *Plunk) subOrUnsubscribeContact(id string, subscribe bool) (*Contact, er
 		return nil, ErrCouldNotUnsubscribeContact
 	}
 
-	p.logInfo(fmt.Sprintf("Contact updated: %s ", id))
+	p.logInfo("contact subscription updated", "id", id, "subscribed", subscribe)
 
 	return result, nil
 }
@@ -0,0 +1,201 @@
+package plunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Number of contacts fetched per request when ListOptions.PageSize is not set.
+const defaultPageSize = 100
+
+// ListOptions controls how ListContacts pages through the contacts of a project.
+type ListOptions struct {
+	PageSize int    // Contacts fetched per request. Defaults to 100.
+	Cursor   string // Resume after the contact with this ID, see ContactIterator.Cursor.
+}
+
+// ContactIterator streams the contacts of a project one page at a time, so
+// that only the contact being read is held in memory.
+//
+//	it := p.ListContacts(plunk.ListOptions{})
+//	defer it.Close()
+//
+//	for it.Next() {
+//		contact := it.Contact()
+//		...
+//	}
+//
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ContactIterator struct {
+	p    *Plunk
+	ctx  context.Context
+	size int
+
+	body    io.ReadCloser
+	dec     *json.Decoder
+	contact *Contact
+	cursor  string
+	err     error
+	done    bool
+
+	read      int    // contacts read from the current page
+	firstID   string // first contact of the current page
+	prevFirst string // first contact of the previous page
+}
+
+// Lists the contacts of your Plunk project page by page. Nothing is fetched
+// until Next is called.
+func (p *Plunk) ListContacts(opts ListOptions) *ContactIterator {
+	return p.ListContactsContext(context.Background(), opts)
+}
+
+// Like ListContacts, but every page request is bound to ctx.
+func (p *Plunk) ListContactsContext(ctx context.Context, opts ListOptions) *ContactIterator {
+	size := opts.PageSize
+	if size <= 0 {
+		size = defaultPageSize
+	}
+
+	return &ContactIterator{
+		p:      p,
+		ctx:    ctx,
+		size:   size,
+		cursor: opts.Cursor,
+	}
+}
+
+// Next advances to the next contact, fetching the next page when the current
+// one is exhausted. It returns false at the end of the list or on error.
+func (it *ContactIterator) Next() bool {
+	for !it.done {
+		if it.dec == nil {
+			if err := it.fetch(); err != nil {
+				it.fail(err)
+				return false
+			}
+		}
+
+		if it.dec.More() {
+			contact := &Contact{}
+			if err := it.dec.Decode(contact); err != nil {
+				it.fail(err)
+				return false
+			}
+
+			if it.read == 0 {
+				// an API that ignores the cursor starts over with the same page
+				if contact.ID == it.prevFirst {
+					it.Close()
+					return false
+				}
+
+				it.firstID = contact.ID
+			}
+
+			if err := contact.ParseData(); err != nil {
+				it.p.logError("could not parse contact data", "id", contact.ID, "error", err)
+			}
+
+			it.read++
+			it.contact = contact
+			it.cursor = contact.ID
+
+			return true
+		}
+
+		if _, err := it.dec.Token(); err != nil {
+			it.fail(err)
+			return false
+		}
+
+		it.closeBody()
+
+		// a short page is the last one, and a page larger than requested
+		// means the API returned every contact at once
+		if it.read != it.size {
+			it.done = true
+		}
+
+		it.prevFirst = it.firstID
+		it.read = 0
+	}
+
+	return false
+}
+
+// Contact returns the contact Next advanced to.
+func (it *ContactIterator) Contact() *Contact {
+	return it.contact
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ContactIterator) Err() error {
+	return it.err
+}
+
+// Cursor returns the ID of the last contact returned by Next. Pass it as
+// ListOptions.Cursor to resume listing after that contact.
+func (it *ContactIterator) Cursor() string {
+	return it.cursor
+}
+
+// Close stops the iteration and releases the page being read. It is safe to
+// call more than once, and must be called when breaking out of a loop early.
+func (it *ContactIterator) Close() error {
+	it.done = true
+	return it.closeBody()
+}
+
+// Requests the page that follows the cursor and reads up to its first contact.
+func (it *ContactIterator) fetch() error {
+	query := url.Values{}
+	query.Set("limit", fmt.Sprint(it.size))
+	if it.cursor != "" {
+		query.Set("cursor", it.cursor)
+	}
+
+	resp, err := it.p.sendRequest(it.ctx, SendConfig{
+		Url:    it.p.url(contactsEndpoint) + "?" + query.Encode(),
+		Method: http.MethodGet,
+	})
+	if err != nil {
+		return err
+	}
+
+	it.body = resp.Body
+	it.dec = json.NewDecoder(resp.Body)
+
+	token, err := it.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != json.Delim('[') {
+		return fmt.Errorf("expected a list of contacts, got %v", token)
+	}
+
+	return nil
+}
+
+func (it *ContactIterator) fail(err error) {
+	it.err = err
+	it.Close()
+}
+
+func (it *ContactIterator) closeBody() error {
+	it.dec = nil
+	if it.body == nil {
+		return nil
+	}
+
+	err := it.body.Close()
+	it.body = nil
+
+	return err
+}
@@ -0,0 +1,175 @@
+package plunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kayode0x/plunk/plunktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a client for a fake holding n contacts, and a count of the pages it fetched.
+func newPagingClient(t *testing.T, n int) (*Plunk, *plunktest.Server, *int32) {
+	t.Helper()
+
+	srv := plunktest.NewServer()
+	t.Cleanup(srv.Close)
+
+	for i := 0; i < n; i++ {
+		srv.AddContact(fmt.Sprintf("user%d@example.com", i), true, nil)
+	}
+
+	var pages int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&pages, 1)
+		srv.ServeHTTP(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	p, err := New(srv.ApiKey, &Config{BaseUrl: proxy.URL})
+	assert.Nil(t, err)
+
+	return p, srv, &pages
+}
+
+func TestListContacts(t *testing.T) {
+	p, _, pages := newPagingClient(t, 250)
+
+	it := p.ListContacts(ListOptions{PageSize: 100})
+	defer it.Close()
+
+	var emails []string
+	for it.Next() {
+		emails = append(emails, it.Contact().Email)
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Len(t, emails, 250)
+	assert.Equal(t, "user0@example.com", emails[0])
+	assert.Equal(t, "user249@example.com", emails[249])
+	assert.Equal(t, int32(3), atomic.LoadInt32(pages))
+
+	// a full last page takes one more request to find out it was the last
+	p, _, pages = newPagingClient(t, 200)
+
+	it = p.ListContacts(ListOptions{PageSize: 100})
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 200, count)
+	assert.Equal(t, int32(3), atomic.LoadInt32(pages))
+}
+
+func TestListContactsCursor(t *testing.T) {
+	p, srv, _ := newPagingClient(t, 10)
+	contacts := srv.Contacts()
+
+	it := p.ListContacts(ListOptions{PageSize: 3, Cursor: contacts[6].ID})
+	defer it.Close()
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Contact().ID)
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []string{contacts[7].ID, contacts[8].ID, contacts[9].ID}, ids)
+	assert.Equal(t, contacts[9].ID, it.Cursor())
+}
+
+func TestListContactsStopsEarly(t *testing.T) {
+	p, _, pages := newPagingClient(t, 250)
+
+	it := p.ListContacts(ListOptions{PageSize: 100})
+
+	count := 0
+	for it.Next() {
+		count++
+		if count == 5 {
+			break
+		}
+	}
+
+	assert.Nil(t, it.Close())
+	assert.Nil(t, it.Close())
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Err())
+	assert.Equal(t, int32(1), atomic.LoadInt32(pages))
+}
+
+func TestListContactsError(t *testing.T) {
+	p, srv, _ := newPagingClient(t, 15)
+
+	it := p.ListContacts(ListOptions{PageSize: 10})
+	defer it.Close()
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, it.Next())
+	}
+
+	srv.Fail("GET /contacts", plunktest.InternalServerError())
+
+	assert.False(t, it.Next())
+	assert.Equal(t, "Plunk Error (Code: 500, Error: Internal Server Error, Message: Something went wrong)", it.Err().Error())
+
+	// the iterator can be resumed from where it stopped
+	it = p.ListContacts(ListOptions{PageSize: 10, Cursor: it.Cursor()})
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 5, count)
+}
+
+func TestListContactsWithoutPaging(t *testing.T) {
+	// an API that ignores limit and cursor returns every contact on every request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":"1","email":"a@example.com"},{"id":"2","email":"b@example.com"}]`)
+	}))
+	defer server.Close()
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL})
+	assert.Nil(t, err)
+
+	// pages larger than requested, and pages that start over, both end the list
+	for _, size := range []int{1, 2} {
+		it := p.ListContacts(ListOptions{PageSize: size})
+
+		var ids []string
+		for it.Next() {
+			ids = append(ids, it.Contact().ID)
+		}
+
+		assert.Nil(t, it.Err())
+		assert.Equal(t, []string{"1", "2"}, ids)
+		it.Close()
+	}
+}
+
+func TestListContactsContextCanceled(t *testing.T) {
+	p, _, pages := newPagingClient(t, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	it := p.ListContactsContext(ctx, ListOptions{})
+	defer it.Close()
+
+	assert.False(t, it.Next())
+	assert.True(t, errors.Is(it.Err(), context.Canceled))
+	assert.Equal(t, int32(0), atomic.LoadInt32(pages))
+}
@@ -1,36 +1,18 @@
 package plunk
 
 import (
-	"log"
-	"os"
+	"context"
+	"errors"
 	"testing"
 
-	"github.com/joho/godotenv"
+	"github.com/kayode0x/plunk/plunktest"
 	"github.com/stretchr/testify/assert"
 )
 
-var opts = &Config{
-	Debug: true,
-}
-
-func getEnvVariable(key string) string {
-	err := godotenv.Load(".env")
-
-	if err != nil {
-		log.Fatalf("Error loading .env file")
-	}
-
-	return os.Getenv(key)
-}
-
-var (
-	testEmail = "user@example.com"
-	secretKey = getEnvVariable("PLUNK_SECRET_KEY")
-)
+var testEmail = "user@example.com"
 
 func TestGetContact(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	payload := CreateContactPayload{
 		Email: testEmail,
@@ -49,26 +31,47 @@ func TestGetContact(t *testing.T) {
 }
 
 func TestGetContacts(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	contacts, err := p.GetContacts()
 	assert.Nil(t, err)
 	assert.NotNil(t, contacts)
+	assert.Len(t, contacts, 0)
+
+	_, err = p.CreateContact(CreateContactPayload{
+		Email: testEmail,
+		Data:  map[string]interface{}{"plan": "pro"},
+	})
+	assert.Nil(t, err)
+
+	_, err = p.CreateContact(CreateContactPayload{Email: "user2@example.com"})
+	assert.Nil(t, err)
+
+	contacts, err = p.GetContacts()
+	assert.Nil(t, err)
+	assert.Len(t, contacts, 2)
+	assert.Equal(t, testEmail, contacts[0].Email)
+	assert.Equal(t, "pro", contacts[0].Data["plan"])
+	assert.Nil(t, contacts[1].Data)
 }
 
 func TestGetContactsCount(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	count, err := p.GetContactsCount()
 	assert.Nil(t, err)
-	assert.NotNil(t, count)
+	assert.Equal(t, 0, count)
+
+	_, err = p.CreateContact(CreateContactPayload{Email: testEmail})
+	assert.Nil(t, err)
+
+	count, err = p.GetContactsCount()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
 }
 
 func TestCreateContact(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	data := map[string]interface{}{
 		"first_name": "John",
@@ -97,8 +100,7 @@ func TestCreateContact(t *testing.T) {
 }
 
 func TestUpdateContact(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	data := map[string]interface{}{
 		"first_name": "John",
@@ -146,8 +148,7 @@ func TestUpdateContact(t *testing.T) {
 }
 
 func TestDeleteContact(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	payload := CreateContactPayload{
 		Email: testEmail,
@@ -168,8 +169,7 @@ func TestDeleteContact(t *testing.T) {
 }
 
 func TestSubOrUnsubscribeContact(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	payload := CreateContactPayload{
 		Email: testEmail,
@@ -198,7 +198,7 @@ func TestSubOrUnsubscribeContact(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		newContact, err := p.subOrUnsubscribeContact(contact.ID, test.sub)
+		newContact, err := p.subOrUnsubscribeContact(context.Background(), contact.ID, test.sub)
 		assert.Nil(t, err)
 		assert.NotNil(t, newContact)
 		assert.Equal(t, newContact.Email, testEmail)
@@ -208,3 +208,98 @@ func TestSubOrUnsubscribeContact(t *testing.T) {
 	_, err = p.DeleteContact(contact.ID)
 	assert.Nil(t, err)
 }
+
+func TestContactErrors(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	_, err := p.CreateContact(CreateContactPayload{Email: testEmail})
+	assert.Nil(t, err)
+
+	_, err = p.CreateContact(CreateContactPayload{Email: testEmail})
+	assert.True(t, errors.Is(err, ErrContactAlreadyExists))
+	assert.False(t, errors.Is(err, ErrContactNotFound))
+
+	var apiErr *CustomError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 500, apiErr.Code)
+
+	_, err = p.GetContact("unknown")
+	assert.True(t, errors.Is(err, ErrContactNotFound))
+	assert.False(t, errors.Is(err, ErrContactAlreadyExists))
+}
+
+func TestGetContactByEmail(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	_, err := p.CreateContact(CreateContactPayload{Email: "other@example.com"})
+	assert.Nil(t, err)
+
+	created, err := p.CreateContact(CreateContactPayload{
+		Email: testEmail,
+		Data:  map[string]interface{}{"plan": "pro"},
+	})
+	assert.Nil(t, err)
+
+	contact, err := p.GetContactByEmail("User@Example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, created.ID, contact.ID)
+	assert.Equal(t, "pro", contact.Data["plan"])
+
+	contact, err = p.GetContactByEmail("unknown@example.com")
+	assert.Equal(t, ErrContactNotFound, err)
+	assert.Nil(t, contact)
+
+	contact, err = p.GetContactByEmail("")
+	assert.Equal(t, ErrMissingEmail, err)
+	assert.Nil(t, contact)
+}
+
+func TestUpsertContact(t *testing.T) {
+	p, srv := newTestClient(t)
+	subscribed, unsubscribed := true, false
+
+	contact, created, err := p.UpsertContact(UpsertContactPayload{
+		Email:      testEmail,
+		Subscribed: &subscribed,
+		Data:       map[string]interface{}{"plan": "free"},
+	})
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.True(t, contact.Subscribed)
+	assert.Equal(t, "free", contact.Data["plan"])
+	id := contact.ID
+
+	contact, created, err = p.UpsertContact(UpsertContactPayload{
+		Email: testEmail,
+		Data:  map[string]interface{}{"plan": "pro"},
+	})
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, id, contact.ID)
+	assert.Equal(t, "pro", contact.Data["plan"])
+
+	// without a status, the stored one is kept
+	assert.True(t, contact.Subscribed)
+
+	// without data, the stored data is kept
+	contact, created, err = p.UpsertContact(UpsertContactPayload{Email: testEmail, Subscribed: &unsubscribed})
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.False(t, contact.Subscribed)
+	assert.Equal(t, "pro", contact.Data["plan"])
+
+	assert.Len(t, srv.Contacts(), 1)
+
+	// new contacts without a status are created unsubscribed
+	contact, created, err = p.UpsertContact(UpsertContactPayload{Email: "new@example.com"})
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.False(t, contact.Subscribed)
+
+	_, _, err = p.UpsertContact(UpsertContactPayload{})
+	assert.Equal(t, ErrMissingEmail, err)
+
+	srv.Fail("POST /contacts", plunktest.InternalServerError())
+	_, _, err = p.UpsertContact(UpsertContactPayload{Email: "other@example.com"})
+	assert.Equal(t, "Plunk Error (Code: 500, Error: Internal Server Error, Message: Something went wrong)", err.Error())
+}
@@ -2,9 +2,11 @@ package plunk
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 )
 
 // Define a struct to represent the JSON error data
@@ -19,6 +21,21 @@ func (e *CustomError) Error() string {
 	return fmt.Sprintf("Plunk Error (Code: %d, Error: %s, Message: %s)", e.Code, e.Type, e.Message)
 }
 
+// Is lets errors.Is match API errors against the sentinel errors they stand
+// for, e.g. ErrContactAlreadyExists when creating a duplicate contact.
+func (e *CustomError) Is(target error) bool {
+	message := strings.ToLower(e.Message)
+
+	switch target {
+	case ErrContactAlreadyExists:
+		return strings.Contains(message, "contact already exists")
+	case ErrContactNotFound:
+		return e.Code == http.StatusNotFound && strings.Contains(message, "contact")
+	}
+
+	return false
+}
+
 func parseAPIError(resp *http.Response) error {
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 		return nil
@@ -37,3 +54,60 @@ func parseAPIError(resp *http.Response) error {
 
 	return &plunkError
 }
+
+// BatchError is returned when one or more emails of a batch could not be sent.
+// errors.Is and errors.As match against the error of every failed email.
+type BatchError struct {
+	Total  int           // number of emails in the batch
+	Failed []BatchResult // results of the emails that failed, in input order
+}
+
+func newBatchError(results []BatchResult) error {
+	failed := []BatchResult{}
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &BatchError{Total: len(results), Failed: failed}
+}
+
+func (e *BatchError) Error() string {
+	first := e.Failed[0]
+	return fmt.Sprintf("%d of %d emails failed (email %d: %s)", len(e.Failed), e.Total, first.Index, first.Err.Error())
+}
+
+// Errors returns the error of every failed email, in input order.
+func (e *BatchError) Errors() []error {
+	errs := make([]error, len(e.Failed))
+	for i, r := range e.Failed {
+		errs[i] = r.Err
+	}
+
+	return errs
+}
+
+func (e *BatchError) Is(target error) bool {
+	for _, r := range e.Failed {
+		if errors.Is(r.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *BatchError) As(target interface{}) bool {
+	for _, r := range e.Failed {
+		if errors.As(r.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,8 @@
 package plunk
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -88,3 +90,26 @@ func TestParseAPIError(t *testing.T) {
 		}
 	}
 }
+
+func TestBatchError(t *testing.T) {
+	results := []BatchResult{
+		{Index: 0},
+		{Index: 1, Err: context.Canceled},
+		{Index: 2, Err: &CustomError{Code: 429, Type: "Too Many Requests", Message: "slow down"}},
+	}
+
+	err := newBatchError(results)
+	assert.NotNil(t, err)
+	assert.Equal(t, "2 of 3 emails failed (email 1: context canceled)", err.Error())
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, errors.Is(err, context.DeadlineExceeded))
+
+	var plunkErr *CustomError
+	assert.True(t, errors.As(err, &plunkErr))
+	assert.Equal(t, 429, plunkErr.Code)
+
+	batchErr := err.(*BatchError)
+	assert.Equal(t, []error{results[1].Err, results[2].Err}, batchErr.Errors())
+
+	assert.Nil(t, newBatchError(results[:1]))
+}
@@ -1,6 +1,7 @@
 package plunk
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,6 +12,11 @@ type EventPayload struct {
 	Email      string         `json:"email"`
 	Data       map[string]any `json:"data"`       // See: https://docs.useplunk.com/guides/linking-data-to-contacts#linking-data-on-event-triggers
 	Subscribed bool           `json:"subscribed"` // When you trigger an event for a contact, they will automatically be subscribed unless you pass subscribed: false along with the event.
+
+	// Identifies this trigger across retries. When a key has already been
+	// sent, the event is not triggered again and the stored response is
+	// returned instead. See Config.Idempotency.
+	IdempotencyKey string `json:"-"`
 }
 
 type EventResponse struct {
@@ -27,6 +33,19 @@ type Event struct {
 	ProjectID  string `json:"projectId"`
 	CampaignID string `json:"campaignId"`
 	TemplateID string `json:"templateId"`
+
+	// Every time a contact triggered the event. Only filled in by ListEvents
+	// and GetEvent.
+	Triggers []Trigger `json:"triggers,omitempty"`
+}
+
+// A trigger records a contact triggering an event.
+type Trigger struct {
+	ID        string `json:"id"`
+	ContactID string `json:"contactId"`
+	EventID   string `json:"eventId"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
 }
 
 var (
@@ -34,10 +53,16 @@ var (
 	ErrMissingEmail        = errors.New("missing email")
 	ErrMissingEventID      = errors.New("missing event id")
 	ErrCouldNotDeleteEvent = errors.New("could not delete event")
+	ErrEventNotFound       = errors.New("event not found")
 )
 
 // Triggers an event and creates it if it doesn't exist.
 func (p *Plunk) TriggerEvent(payload EventPayload) (*EventResponse, error) {
+	return p.TriggerEventContext(context.Background(), payload)
+}
+
+// Like TriggerEvent, but the request is bound to ctx.
+func (p *Plunk) TriggerEventContext(ctx context.Context, payload EventPayload) (*EventResponse, error) {
 	// validate payload
 	if payload.Event == "" {
 		return nil, ErrMissingEvent
@@ -47,12 +72,25 @@ func (p *Plunk) TriggerEvent(payload EventPayload) (*EventResponse, error) {
 		return nil, ErrMissingEmail
 	}
 
+	if err := p.validateEmail("Email", 0, &payload.Email); err != nil {
+		return nil, err
+	}
+
 	result := &EventResponse{}
+	if ok, err := p.storedResponse(ctx, eventsEndpoint, payload.IdempotencyKey, result); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	}
+
 	url := p.url(eventsEndpoint)
-	resp, err := p.sendRequest(SendConfig{
-		Url:    url,
-		Method: http.MethodPost,
-		Body:   payload,
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:            url,
+		Method:         http.MethodPost,
+		Body:           payload,
+		IdempotencyKey: payload.IdempotencyKey,
 	})
 	if err != nil {
 		return nil, err
@@ -63,19 +101,25 @@ func (p *Plunk) TriggerEvent(payload EventPayload) (*EventResponse, error) {
 		return nil, err
 	}
 
-	p.logInfo(fmt.Sprintf("Event triggered: %s ", payload.Event))
+	p.storeResponse(eventsEndpoint, payload.IdempotencyKey, result)
+	p.logInfo("event triggered", "event", payload.Event)
 
 	return result, nil
 }
 
 // Deletes an event.
 func (p *Plunk) DeleteEvent(id string) (*Event, error) {
+	return p.DeleteEventContext(context.Background(), id)
+}
+
+// Like DeleteEvent, but the request is bound to ctx.
+func (p *Plunk) DeleteEventContext(ctx context.Context, id string) (*Event, error) {
 	if id == "" {
 		return nil, ErrMissingEventID
 	}
 
 	url := p.url(deleteEventEndpoint)
-	resp, err := p.sendRequest(SendConfig{
+	resp, err := p.sendRequest(ctx, SendConfig{
 		Url:    url,
 		Method: http.MethodDelete,
 		Body:   map[string]string{"id": id},
@@ -90,7 +134,94 @@ func (p *Plunk) DeleteEvent(id string) (*Event, error) {
 		return nil, err
 	}
 
-	p.logInfo(fmt.Sprintf("Event deleted: %s ", id))
+	p.logInfo("event deleted", "id", id)
+
+	return result, nil
+}
+
+// Get a list of all events in your Plunk project, along with their triggers.
+func (p *Plunk) ListEvents() ([]*Event, error) {
+	return p.ListEventsContext(context.Background())
+}
+
+// Like ListEvents, but the request is bound to ctx.
+func (p *Plunk) ListEventsContext(ctx context.Context) ([]*Event, error) {
+	result := []*Event{}
+	url := p.url(listEventsEndpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodGet,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("events retrieved", "count", len(result))
+
+	return result, nil
+}
+
+// Gets the details of a specific event, along with its triggers.
+func (p *Plunk) GetEvent(id string) (*Event, error) {
+	return p.GetEventContext(context.Background(), id)
+}
+
+// Like GetEvent, but the request is bound to ctx.
+func (p *Plunk) GetEventContext(ctx context.Context, id string) (*Event, error) {
+	if id == "" {
+		return nil, ErrMissingEventID
+	}
+
+	result := &Event{}
+	endpoint := fmt.Sprintf("%s/%s", listEventsEndpoint, id)
+	url := p.url(endpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodGet,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("event retrieved", "id", id)
 
 	return result, nil
 }
+
+// Finds the event with the given name. Returns ErrEventNotFound when the
+// project has no such event.
+func (p *Plunk) FindEventByName(name string) (*Event, error) {
+	return p.FindEventByNameContext(context.Background(), name)
+}
+
+// Like FindEventByName, but the request is bound to ctx.
+func (p *Plunk) FindEventByNameContext(ctx context.Context, name string) (*Event, error) {
+	if name == "" {
+		return nil, ErrMissingEvent
+	}
+
+	events, err := p.ListEventsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		if event.Name == name {
+			return event, nil
+		}
+	}
+
+	return nil, ErrEventNotFound
+}
@@ -1,6 +1,8 @@
 package plunk
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -15,8 +17,7 @@ var (
 )
 
 func TestTriggerEvent(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	payload := EventPayload{
 		Event: testEvent,
@@ -79,8 +80,7 @@ func TestTriggerEvent(t *testing.T) {
 }
 
 func TestDeleteEvent(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	payload := EventPayload{
 		Event: testEvent,
@@ -135,3 +135,90 @@ func TestDeleteEvent(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, event)
 }
+
+func TestTriggerEventContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not reach the server")
+	}))
+	defer server.Close()
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp, err := p.TriggerEventContext(ctx, EventPayload{
+		Event: testEvent,
+		Email: eventTestEmail,
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestListEvents(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	events, err := p.ListEvents()
+	assert.Nil(t, err)
+	assert.Empty(t, events)
+
+	first, err := p.TriggerEvent(EventPayload{Event: testEvent, Email: eventTestEmail})
+	assert.Nil(t, err)
+
+	_, err = p.TriggerEvent(EventPayload{Event: testEvent, Email: "other@example.com"})
+	assert.Nil(t, err)
+
+	_, err = p.TriggerEvent(EventPayload{Event: "other_event", Email: eventTestEmail})
+	assert.Nil(t, err)
+
+	events, err = p.ListEvents()
+	assert.Nil(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, first.Event, events[0].ID)
+	assert.Equal(t, testEvent, events[0].Name)
+	assert.Len(t, events[0].Triggers, 2)
+	assert.Equal(t, first.Contact, events[0].Triggers[0].ContactID)
+	assert.Equal(t, first.Event, events[0].Triggers[0].EventID)
+	assert.Equal(t, "other_event", events[1].Name)
+	assert.Len(t, events[1].Triggers, 1)
+}
+
+func TestGetEvent(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	resp, err := p.TriggerEvent(EventPayload{Event: testEvent, Email: eventTestEmail})
+	assert.Nil(t, err)
+
+	event, err := p.GetEvent(resp.Event)
+	assert.Nil(t, err)
+	assert.Equal(t, resp.Event, event.ID)
+	assert.Equal(t, testEvent, event.Name)
+	assert.Len(t, event.Triggers, 1)
+
+	event, err = p.GetEvent("")
+	assert.Equal(t, ErrMissingEventID, err)
+	assert.Nil(t, event)
+
+	_, err = p.GetEvent("unknown")
+	assert.Equal(t, "Plunk Error (Code: 404, Error: Not Found, Message: That event was not found)", err.Error())
+}
+
+func TestFindEventByName(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	resp, err := p.TriggerEvent(EventPayload{Event: testEvent, Email: eventTestEmail})
+	assert.Nil(t, err)
+
+	event, err := p.FindEventByName(testEvent)
+	assert.Nil(t, err)
+	assert.Equal(t, resp.Event, event.ID)
+
+	event, err = p.FindEventByName("unknown")
+	assert.Equal(t, ErrEventNotFound, err)
+	assert.Nil(t, event)
+
+	event, err = p.FindEventByName("")
+	assert.Equal(t, ErrMissingEvent, err)
+	assert.Nil(t, event)
+}
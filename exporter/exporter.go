@@ -0,0 +1,202 @@
+// Package exporter writes the contacts of a Plunk project to CSV or JSON
+// Lines, e.g. to back them up or load them into a warehouse.
+//
+// Contacts are streamed page by page and written as they arrive, so exports
+// of any size run in constant memory.
+//
+//	f, _ := os.Create("contacts.csv")
+//	defer f.Close()
+//
+//	n, err := exporter.Export(ctx, p, f, exporter.Options{Format: exporter.FormatCSV})
+package exporter
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/kayode0x/plunk"
+)
+
+// Format is the format of the output.
+type Format string
+
+const (
+	// A header row followed by one row per contact. The columns are id,
+	// email and subscribed, then one column per flattened data key, named
+	// "data" + Separator + key.
+	FormatCSV Format = "csv"
+	// One JSON object per line, shaped like plunk.Contact, with its data
+	// under "data".
+	FormatJSONL Format = "jsonl"
+)
+
+// Options configures an export.
+type Options struct {
+	Format Format
+
+	// Joins the keys of nested data, e.g. {"address": {"city": "Lagos"}}
+	// becomes "address.city". Defaults to ".".
+	Separator string
+
+	// Flattened data keys to write as CSV columns, in order; other keys are
+	// left out. When nil, the contacts are listed twice: once to find every
+	// key, sorted by name, and once to write them. Keys that first appear
+	// between the two passes are left out.
+	Columns []string
+
+	// Flatten the data of JSONL contacts too. CSV data is always flattened.
+	Flatten bool
+
+	// Contacts fetched per request. See plunk.ListOptions.
+	PageSize int
+}
+
+var ErrUnknownFormat = errors.New("unknown output format")
+
+// Writes every contact of the project to w, returning how many were written.
+func Export(ctx context.Context, p *plunk.Plunk, w io.Writer, opts Options) (int, error) {
+	if opts.Separator == "" {
+		opts.Separator = "."
+	}
+
+	switch opts.Format {
+	case FormatCSV:
+		return exportCSV(ctx, p, w, opts)
+	case FormatJSONL:
+		return exportJSONL(ctx, p, w, opts)
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrUnknownFormat, opts.Format)
+}
+
+// Calls fn with every contact of the project, in order.
+func each(ctx context.Context, p *plunk.Plunk, opts Options, fn func(*plunk.Contact) error) error {
+	it := p.ListContactsContext(ctx, plunk.ListOptions{PageSize: opts.PageSize})
+	defer it.Close()
+
+	for it.Next() {
+		if err := fn(it.Contact()); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// Lists every flattened data key of the project, sorted.
+func discoverColumns(ctx context.Context, p *plunk.Plunk, opts Options) ([]string, error) {
+	keys := map[string]bool{}
+
+	err := each(ctx, p, opts, func(c *plunk.Contact) error {
+		for key := range flatten(c.Data, opts.Separator) {
+			keys[key] = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(keys))
+	for key := range keys {
+		columns = append(columns, key)
+	}
+	sort.Strings(columns)
+
+	return columns, nil
+}
+
+func exportCSV(ctx context.Context, p *plunk.Plunk, w io.Writer, opts Options) (int, error) {
+	columns := opts.Columns
+	if columns == nil {
+		var err error
+		if columns, err = discoverColumns(ctx, p, opts); err != nil {
+			return 0, err
+		}
+	}
+
+	cw := csv.NewWriter(w)
+
+	header := []string{"id", "email", "subscribed"}
+	for _, column := range columns {
+		header = append(header, "data"+opts.Separator+column)
+	}
+
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	record := make([]string, len(header))
+
+	err := each(ctx, p, opts, func(c *plunk.Contact) error {
+		record[0] = c.ID
+		record[1] = c.Email
+		record[2] = strconv.FormatBool(c.Subscribed)
+
+		data := flatten(c.Data, opts.Separator)
+		for i, column := range columns {
+			value, err := cell(data[column])
+			if err != nil {
+				return err
+			}
+			record[3+i] = value
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+		n++
+
+		return nil
+	})
+
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+
+	return n, err
+}
+
+// A contact as written to JSONL.
+type jsonlContact struct {
+	ID         string                 `json:"id"`
+	Email      string                 `json:"email"`
+	Subscribed bool                   `json:"subscribed"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+func exportJSONL(ctx context.Context, p *plunk.Plunk, w io.Writer, opts Options) (int, error) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	n := 0
+	err := each(ctx, p, opts, func(c *plunk.Contact) error {
+		data := c.Data
+		if opts.Flatten && data != nil {
+			data = flatten(data, opts.Separator)
+		}
+
+		if err := enc.Encode(jsonlContact{
+			ID:         c.ID,
+			Email:      c.Email,
+			Subscribed: c.Subscribed,
+			Data:       data,
+		}); err != nil {
+			return err
+		}
+		n++
+
+		return nil
+	})
+
+	return n, err
+}
@@ -0,0 +1,117 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kayode0x/plunk"
+	"github.com/kayode0x/plunk/plunktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T) (*plunk.Plunk, *plunktest.Server) {
+	t.Helper()
+
+	srv := plunktest.NewServer()
+	t.Cleanup(srv.Close)
+
+	p, err := plunk.New(srv.ApiKey, &plunk.Config{BaseUrl: srv.BaseUrl})
+	assert.Nil(t, err)
+
+	srv.AddContact("first@example.com", true, map[string]interface{}{
+		"name":    "First",
+		"seats":   3,
+		"address": map[string]interface{}{"city": "Lagos", "geo": map[string]interface{}{"lat": 6.5}},
+		"tags":    []interface{}{"beta", "vip"},
+	})
+	srv.AddContact("second@example.com", false, map[string]interface{}{
+		"name":  "Second, Jr.",
+		"admin": true,
+	})
+	srv.AddContact("third@example.com", true, nil)
+
+	return p, srv
+}
+
+func TestExportCSV(t *testing.T) {
+	p, srv := newTestClient(t)
+	contacts := srv.Contacts()
+
+	var b bytes.Buffer
+	n, err := Export(context.Background(), p, &b, Options{Format: FormatCSV, PageSize: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+
+	expected := strings.Join([]string{
+		"id,email,subscribed,data.address.city,data.address.geo.lat,data.admin,data.name,data.seats,data.tags",
+		contacts[0].ID + `,first@example.com,true,Lagos,6.5,,First,3,"[""beta"",""vip""]"`,
+		contacts[1].ID + `,second@example.com,false,,,true,"Second, Jr.",,`,
+		contacts[2].ID + ",third@example.com,true,,,,,,",
+	}, "\n") + "\n"
+	assert.Equal(t, expected, b.String())
+}
+
+func TestExportCSVColumns(t *testing.T) {
+	p, srv := newTestClient(t)
+	contacts := srv.Contacts()
+
+	var b bytes.Buffer
+	n, err := Export(context.Background(), p, &b, Options{
+		Format:    FormatCSV,
+		Separator: "_",
+		Columns:   []string{"name", "address_city", "missing"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+
+	expected := strings.Join([]string{
+		"id,email,subscribed,data_name,data_address_city,data_missing",
+		contacts[0].ID + ",first@example.com,true,First,Lagos,",
+		contacts[1].ID + `,second@example.com,false,"Second, Jr.",,`,
+		contacts[2].ID + ",third@example.com,true,,,",
+	}, "\n") + "\n"
+	assert.Equal(t, expected, b.String())
+}
+
+func TestExportJSONL(t *testing.T) {
+	p, srv := newTestClient(t)
+	contacts := srv.Contacts()
+
+	var b bytes.Buffer
+	n, err := Export(context.Background(), p, &b, Options{Format: FormatJSONL})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, `{"id":"`+contacts[0].ID+`","email":"first@example.com","subscribed":true,"data":{"address":{"city":"Lagos","geo":{"lat":6.5}},"name":"First","seats":3,"tags":["beta","vip"]}}`, lines[0])
+	assert.Equal(t, `{"id":"`+contacts[2].ID+`","email":"third@example.com","subscribed":true,"data":null}`, lines[2])
+
+	b.Reset()
+	_, err = Export(context.Background(), p, &b, Options{Format: FormatJSONL, Flatten: true, Separator: "/"})
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(b.String(), `{"id":"`+contacts[0].ID+`","email":"first@example.com","subscribed":true,"data":{"address/city":"Lagos","address/geo/lat":6.5,`))
+}
+
+func TestExportErrors(t *testing.T) {
+	p, srv := newTestClient(t)
+
+	_, err := Export(context.Background(), p, &bytes.Buffer{}, Options{})
+	assert.True(t, errors.Is(err, ErrUnknownFormat))
+
+	srv.Fail("GET /contacts", plunktest.InternalServerError())
+
+	var b bytes.Buffer
+	n, err := Export(context.Background(), p, &b, Options{Format: FormatJSONL})
+	assert.Equal(t, "Plunk Error (Code: 500, Error: Internal Server Error, Message: Something went wrong)", err.Error())
+	assert.Equal(t, 0, n)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = Export(ctx, p, &b, Options{Format: FormatCSV})
+	assert.True(t, errors.Is(err, context.Canceled))
+}
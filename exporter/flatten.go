@@ -0,0 +1,56 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Flattens nested maps into a single level, joining keys with sep. Other
+// values, arrays included, are kept as they are.
+func flatten(data map[string]interface{}, sep string) map[string]interface{} {
+	flat := make(map[string]interface{}, len(data))
+	flattenInto(flat, "", data, sep)
+
+	return flat
+}
+
+func flattenInto(flat map[string]interface{}, prefix string, data map[string]interface{}, sep string) {
+	for k, v := range data {
+		key := k
+		if prefix != "" {
+			key = prefix + sep + k
+		}
+
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenInto(flat, key, nested, sep)
+			continue
+		}
+
+		flat[key] = v
+	}
+}
+
+// Formats a flattened value as a CSV cell.
+func cell(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	}
+
+	// arrays and empty objects are written as JSON
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("could not format %v: %w", v, err)
+	}
+
+	return string(b), nil
+}
@@ -5,10 +5,13 @@ go 1.19
 require (
 	github.com/joho/godotenv v1.5.1
 	github.com/stretchr/testify v1.8.2
+	github.com/yuin/goldmark v1.7.8
+	golang.org/x/net v0.21.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,260 @@
+package plunk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kayode0x/plunk/internal/fsutil"
+)
+
+// How long responses are kept unless Config.IdempotencyTTL is set.
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyStore keeps the responses of requests sent with an idempotency
+// key, so that sending the same key again returns the first response instead
+// of sending a duplicate email or event. Set it as Config.Idempotency.
+//
+// The client calls Get before sending and Set once the request succeeded, from
+// many goroutines at once. Keys are not reserved while a request is in
+// flight, so two calls with the same key that run at the same time may both
+// be sent, whatever the store.
+//
+// A store shared by several processes, e.g. on top of Redis, must make an
+// entry visible to every process's Get as soon as Set returns, and keep it
+// until ttl has passed rather than evict it early. When two Sets race on a
+// key, setting only if absent, e.g. with SET NX, keeps the first response, so
+// every later call returns the same one.
+type IdempotencyStore interface {
+	// Returns the response stored under key, or false when there is none or
+	// it has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Stores response under key, until ttl has passed.
+	Set(ctx context.Context, key string, response []byte, ttl time.Duration) error
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore. Its keys are lost
+// when the process exits. The zero value is ready to use.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]idempotencyEntry
+	swept   int // number of entries after the last sweep
+}
+
+type idempotencyEntry struct {
+	Expires  time.Time       `json:"expires"`
+	Response json.RawMessage `json:"response"`
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: map[string]idempotencyEntry{}}
+}
+
+func (s *MemoryIdempotencyStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !time.Now().Before(entry.Expires) {
+		return nil, false, nil
+	}
+
+	return entry.Response, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Set(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries == nil {
+		s.entries = map[string]idempotencyEntry{}
+	}
+
+	s.entries[key] = idempotencyEntry{Expires: now.Add(ttl), Response: append([]byte(nil), response...)}
+
+	// expired entries are dropped whenever the store has doubled in size, so
+	// that sweeping costs O(1) per Set over time
+	if len(s.entries) >= 2*s.swept {
+		for k, entry := range s.entries {
+			if !now.Before(entry.Expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.swept = len(s.entries)
+	}
+
+	return nil
+}
+
+// FileIdempotencyStore is an IdempotencyStore that keeps every key in its own
+// file in a directory, so keys survive restarts. Expired files are removed
+// when they are read, or by Prune.
+type FileIdempotencyStore struct {
+	dir string
+}
+
+// Returns a store writing to dir, which is created if it does not exist.
+func NewFileIdempotencyStore(dir string) (*FileIdempotencyStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &FileIdempotencyStore{dir: dir}, nil
+}
+
+// Keys may hold any character, so files are named after their hash.
+func (s *FileIdempotencyStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (s *FileIdempotencyStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	path := s.path(key)
+
+	entry, err := readIdempotencyEntry(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !time.Now().Before(entry.Expires) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, false, err
+		}
+
+		return nil, false, nil
+	}
+
+	return entry.Response, true, nil
+}
+
+func (s *FileIdempotencyStore) Set(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	b, err := json.Marshal(idempotencyEntry{Expires: time.Now().Add(ttl), Response: response})
+	if err != nil {
+		return err
+	}
+
+	// written to a temporary file first, so that a crash never leaves a
+	// partial entry behind
+	path := s.path(key)
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	// the entry is only durable once the rename is
+	return fsutil.SyncDir(s.dir)
+}
+
+// Removes the files of every expired key.
+func (s *FileIdempotencyStore) Prune() error {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+
+		path := filepath.Join(s.dir, f.Name())
+		entry, err := readIdempotencyEntry(path)
+		if err != nil || now.Before(entry.Expires) {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readIdempotencyEntry(path string) (idempotencyEntry, error) {
+	entry := idempotencyEntry{}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+
+	err = json.Unmarshal(b, &entry)
+	return entry, err
+}
+
+func (p *Plunk) idempotencyTTL() time.Duration {
+	if p.IdempotencyTTL <= 0 {
+		return defaultIdempotencyTTL
+	}
+
+	return p.IdempotencyTTL
+}
+
+// Decodes the response stored for key on endpoint into v, and reports whether
+// there was one. Keys are scoped to their endpoint, so a send and an event may
+// share one.
+func (p *Plunk) storedResponse(ctx context.Context, endpoint, key string, v interface{}) (bool, error) {
+	if p.Idempotency == nil || key == "" {
+		return false, nil
+	}
+
+	b, ok, err := p.Idempotency.Get(ctx, endpoint+" "+key)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return false, err
+	}
+
+	p.logInfo("returning stored response", "path", endpoint, "idempotency_key", key)
+
+	return true, nil
+}
+
+// Stores the response of a request that succeeded. The request has already
+// been sent, so failing to store it is logged rather than returned, and it is
+// stored even if the request's context is done by now.
+func (p *Plunk) storeResponse(endpoint, key string, v interface{}) {
+	if p.Idempotency == nil || key == "" {
+		return
+	}
+
+	b, err := json.Marshal(v)
+	if err == nil {
+		err = p.Idempotency.Set(context.Background(), endpoint+" "+key, b, p.idempotencyTTL())
+	}
+
+	if err != nil {
+		p.logError("could not store response", "path", endpoint, "idempotency_key", key, "error", err)
+	}
+}
@@ -0,0 +1,237 @@
+package plunk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kayode0x/plunk/plunktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIdempotentTestClient(t *testing.T, store IdempotencyStore) (*Plunk, *plunktest.Server) {
+	t.Helper()
+
+	server := plunktest.NewServer()
+	t.Cleanup(server.Close)
+
+	p, err := New(server.ApiKey, &Config{BaseUrl: server.BaseUrl, Idempotency: store})
+	assert.Nil(t, err)
+
+	return p, server
+}
+
+func TestSendTransactionalEmailIdempotencyKey(t *testing.T) {
+	p, server := newIdempotentTestClient(t, NewMemoryIdempotencyStore())
+
+	payload := TransactionalEmailPayload{
+		To:             []string{"test@example.com"},
+		Subject:        "Your order",
+		Body:           "Thanks for your order.",
+		IdempotencyKey: "order-42",
+	}
+
+	first, err := p.SendTransactionalEmail(payload)
+	assert.Nil(t, err)
+
+	second, err := p.SendTransactionalEmail(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, first, second)
+	assert.Len(t, server.Emails(), 1)
+
+	// other keys, and emails without one, are sent
+	payload.IdempotencyKey = "order-43"
+	_, err = p.SendTransactionalEmail(payload)
+	assert.Nil(t, err)
+
+	payload.IdempotencyKey = ""
+	_, err = p.SendTransactionalEmail(payload)
+	assert.Nil(t, err)
+	_, err = p.SendTransactionalEmail(payload)
+	assert.Nil(t, err)
+
+	assert.Len(t, server.Emails(), 4)
+}
+
+func TestSendTransactionalEmailIdempotencyKeyFailure(t *testing.T) {
+	p, server := newIdempotentTestClient(t, NewMemoryIdempotencyStore())
+
+	payload := TransactionalEmailPayload{
+		To:             []string{"test@example.com"},
+		Subject:        "Your order",
+		Body:           "Thanks for your order.",
+		IdempotencyKey: "order-42",
+	}
+
+	// failed requests are not stored, so they can be sent again
+	server.Fail("POST /send", plunktest.Failure{Status: 400, Message: "Bad request", Times: 1})
+	_, err := p.SendTransactionalEmail(payload)
+	assert.NotNil(t, err)
+
+	_, err = p.SendTransactionalEmail(payload)
+	assert.Nil(t, err)
+	assert.Len(t, server.Emails(), 1)
+}
+
+func TestTriggerEventIdempotencyKey(t *testing.T) {
+	p, server := newIdempotentTestClient(t, NewMemoryIdempotencyStore())
+
+	payload := EventPayload{Event: "order-placed", Email: "test@example.com", IdempotencyKey: "order-42"}
+
+	first, err := p.TriggerEvent(payload)
+	assert.Nil(t, err)
+
+	second, err := p.TriggerEvent(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, first, second)
+	assert.Len(t, server.TrackedEvents(), 1)
+
+	// keys are scoped to the endpoint
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:             []string{"test@example.com"},
+		Subject:        "Your order",
+		Body:           "Thanks for your order.",
+		IdempotencyKey: "order-42",
+	})
+	assert.Nil(t, err)
+	assert.Len(t, server.Emails(), 1)
+}
+
+func TestIdempotencyTTL(t *testing.T) {
+	server := plunktest.NewServer()
+	t.Cleanup(server.Close)
+
+	p, err := New(server.ApiKey, &Config{
+		BaseUrl:        server.BaseUrl,
+		Idempotency:    NewMemoryIdempotencyStore(),
+		IdempotencyTTL: 50 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+
+	payload := EventPayload{Event: "order-placed", Email: "test@example.com", IdempotencyKey: "order-42"}
+
+	_, err = p.TriggerEvent(payload)
+	assert.Nil(t, err)
+
+	time.Sleep(60 * time.Millisecond)
+
+	_, err = p.TriggerEvent(payload)
+	assert.Nil(t, err)
+	assert.Len(t, server.TrackedEvents(), 2)
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryIdempotencyStore()
+
+	_, ok, err := s.Get(ctx, "key")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.Nil(t, s.Set(ctx, "key", []byte(`{"success":true}`), time.Hour))
+	assert.Nil(t, s.Set(ctx, "expired", []byte(`{}`), -time.Second))
+
+	response, ok, err := s.Get(ctx, "key")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, `{"success":true}`, string(response))
+
+	_, ok, _ = s.Get(ctx, "expired")
+	assert.False(t, ok)
+
+	// expired entries are swept as the store grows
+	for _, key := range []string{"a", "b", "c"} {
+		assert.Nil(t, s.Set(ctx, key, []byte(`{}`), time.Hour))
+	}
+	_, ok = s.entries["expired"]
+	assert.False(t, ok)
+}
+
+func TestMemoryIdempotencyStoreZeroValue(t *testing.T) {
+	ctx := context.Background()
+	var s MemoryIdempotencyStore
+
+	_, ok, err := s.Get(ctx, "key")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.Nil(t, s.Set(ctx, "key", []byte(`{}`), time.Hour))
+
+	_, ok, err = s.Get(ctx, "key")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestFileIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "keys")
+
+	s, err := NewFileIdempotencyStore(dir)
+	assert.Nil(t, err)
+
+	_, ok, err := s.Get(ctx, "order/42")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.Nil(t, s.Set(ctx, "order/42", []byte(`{"success":true}`), time.Hour))
+	assert.Nil(t, s.Set(ctx, "expired", []byte(`{}`), -time.Second))
+	assert.Nil(t, s.Set(ctx, "also expired", []byte(`{}`), -time.Second))
+
+	// keys survive reopening the store
+	s, err = NewFileIdempotencyStore(dir)
+	assert.Nil(t, err)
+
+	response, ok, err := s.Get(ctx, "order/42")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, `{"success":true}`, string(response))
+
+	_, ok, err = s.Get(ctx, "expired")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	files, _ := os.ReadDir(dir)
+	assert.Len(t, files, 2)
+
+	assert.Nil(t, s.Prune())
+	files, _ = os.ReadDir(dir)
+	assert.Len(t, files, 1)
+
+	// a client restarted with the same directory does not send twice
+	p, server := newIdempotentTestClient(t, s)
+	payload := EventPayload{Event: "order-placed", Email: "test@example.com", IdempotencyKey: "order-42"}
+	_, err = p.TriggerEvent(payload)
+	assert.Nil(t, err)
+
+	s, err = NewFileIdempotencyStore(dir)
+	assert.Nil(t, err)
+
+	p, err = New(server.ApiKey, &Config{BaseUrl: server.BaseUrl, Idempotency: s})
+	assert.Nil(t, err)
+
+	_, err = p.TriggerEvent(payload)
+	assert.Nil(t, err)
+	assert.Len(t, server.TrackedEvents(), 1)
+}
+
+type failingIdempotencyStore struct{}
+
+func (failingIdempotencyStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("store unavailable")
+}
+
+func (failingIdempotencyStore) Set(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	return errors.New("store unavailable")
+}
+
+func TestIdempotencyStoreErrors(t *testing.T) {
+	p, server := newIdempotentTestClient(t, failingIdempotencyStore{})
+
+	// nothing is sent when the store cannot tell whether the key was sent
+	_, err := p.TriggerEvent(EventPayload{Event: "order-placed", Email: "test@example.com", IdempotencyKey: "order-42"})
+	assert.Equal(t, "store unavailable", err.Error())
+	assert.Empty(t, server.TrackedEvents())
+}
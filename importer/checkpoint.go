@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// The contents of a checkpoint file.
+type checkpoint struct {
+	Row int `json:"row"` // every row up to and including this one was processed
+}
+
+// Reads the checkpoint at path. A missing file means nothing was processed yet.
+func loadCheckpoint(path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var c checkpoint
+	if err := json.Unmarshal(b, &c); err != nil {
+		return 0, err
+	}
+
+	return c.Row, nil
+}
+
+// Replaces the checkpoint at path, through a rename so that a crash never
+// leaves a partial file behind.
+func saveCheckpoint(path string, row int) error {
+	b, err := json.Marshal(checkpoint{Row: row})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,433 @@
+// Package importer creates and updates Plunk contacts in bulk from CSV or
+// JSON Lines files.
+//
+// Every row is upserted: contacts that do not exist yet are created, and
+// existing ones get the row's data merged into theirs. Rows are imported
+// concurrently through the given client, so its rate limit and retry policy
+// apply to every request.
+//
+//	f, _ := os.Open("contacts.csv")
+//	defer f.Close()
+//
+//	report, err := importer.Import(ctx, p, f, importer.Options{
+//		Format:     importer.FormatCSV,
+//		Mapping:    importer.Mapping{Email: "Email Address"},
+//		Checkpoint: "contacts.checkpoint",
+//	})
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kayode0x/plunk"
+)
+
+// Format is the format of the input.
+type Format string
+
+const (
+	// Comma-separated values with a header row. See Mapping.
+	FormatCSV Format = "csv"
+	// One JSON object per line, shaped like plunk.CreateContactPayload.
+	// Blank lines are ignored.
+	FormatJSONL Format = "jsonl"
+)
+
+// Mapping tells the importer which CSV columns hold what.
+type Mapping struct {
+	Email      string // Column holding the email address. Defaults to "email".
+	Subscribed string // Column holding the subscription status. Defaults to "subscribed", if present.
+
+	// Data keys and the columns their values are read from. When nil, every
+	// other column is imported under its header. Empty cells are left out.
+	Data map[string]string
+}
+
+// Options configures an import.
+type Options struct {
+	Format  Format
+	Mapping Mapping // CSV only.
+
+	// Subscription status of contacts created from rows that do not set one.
+	// Existing contacts keep theirs.
+	Subscribed bool
+
+	// Number of rows imported at once. Defaults to the client's Concurrency.
+	Concurrency int
+
+	// Path of a file recording how far the import got. When it exists, rows
+	// it covers are not imported again, so an interrupted import can be
+	// resumed by running it again with the same input. Failed and skipped
+	// rows count as processed; find them in the Report.
+	Checkpoint string
+}
+
+// Status is the outcome of importing a row.
+type Status string
+
+const (
+	StatusCreated Status = "created"
+	StatusUpdated Status = "updated"
+	StatusSkipped Status = "skipped" // the row is invalid, and no request was made
+	StatusFailed  Status = "failed"  // the API rejected the row
+)
+
+// RowResult is the outcome of importing a single row.
+type RowResult struct {
+	Row    int // numbered from 1, after the CSV header
+	Email  string
+	Status Status
+	Err    error
+}
+
+// Report sums up an import.
+type Report struct {
+	Created int
+	Updated int
+	Skipped int
+	Failed  int
+	Resumed int // rows covered by the checkpoint, which were not imported again
+
+	Rows []RowResult // skipped and failed rows, in input order
+}
+
+var (
+	ErrEmptyInput        = errors.New("input is empty")
+	ErrUnknownFormat     = errors.New("unknown input format")
+	ErrMissingColumn     = errors.New("missing column")
+	ErrInvalidRow        = errors.New("invalid row")
+	ErrInvalidEmail      = plunk.ErrInvalidEmail
+	ErrInvalidSubscribed = errors.New("invalid subscribed value")
+	ErrDuplicateEmail    = errors.New("email already imported from an earlier row")
+)
+
+// How many processed rows the checkpoint may lag behind.
+const checkpointInterval = 100
+
+// Imports every row of r. The returned report is never nil; an error means
+// the import stopped early, because r could not be read, ctx is done, or the
+// checkpoint could not be written.
+func Import(ctx context.Context, p *plunk.Plunk, r io.Reader, opts Options) (*Report, error) {
+	report := &Report{}
+
+	var (
+		rows rowReader
+		err  error
+	)
+
+	switch opts.Format {
+	case FormatCSV:
+		rows, err = newCSVReader(r, opts.Mapping)
+	case FormatJSONL:
+		rows = newJSONLReader(r)
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownFormat, opts.Format)
+	}
+
+	if err != nil {
+		return report, err
+	}
+
+	resumeAfter := 0
+	if opts.Checkpoint != "" {
+		resumeAfter, err = loadCheckpoint(opts.Checkpoint)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	existing, err := existingContacts(ctx, p)
+	if err != nil {
+		return report, err
+	}
+
+	im := &importer{p: p, opts: opts, existing: existing}
+	return im.run(ctx, rows, resumeAfter, report)
+}
+
+// Like Import, but reads the file at path. When opts.Format is not set, it is
+// taken from the file extension (.csv, .jsonl or .ndjson).
+func ImportFile(ctx context.Context, p *plunk.Plunk, path string, opts Options) (*Report, error) {
+	if opts.Format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			opts.Format = FormatCSV
+		case ".jsonl", ".ndjson":
+			opts.Format = FormatJSONL
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return &Report{}, err
+	}
+	defer f.Close()
+
+	return Import(ctx, p, f, opts)
+}
+
+type importer struct {
+	p        *plunk.Plunk
+	opts     Options
+	existing map[string]string // lowercased email -> contact ID
+}
+
+type outcome struct {
+	RowResult
+	done bool // whether the row counts as processed for the checkpoint
+}
+
+func (im *importer) run(ctx context.Context, rows rowReader, resumeAfter int, report *Report) (*Report, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan row)
+	outcomes := make(chan outcome)
+
+	// rows are read in order on a single goroutine, which makes catching
+	// duplicate emails simple
+	var readErr error
+	go func() {
+		defer close(jobs)
+
+		seen := map[string]bool{}
+		for {
+			r, err := rows.next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				readErr = err
+				cancel()
+				return
+			}
+
+			if r.num <= resumeAfter {
+				if !r.blank {
+					report.Resumed++
+				}
+				continue
+			}
+
+			if r.err == nil && !r.blank {
+				r.email, r.err = im.normalizeEmail(r.email)
+			}
+
+			if r.err == nil && !r.blank {
+				key := strings.ToLower(r.email)
+				if seen[key] {
+					r.err = ErrDuplicateEmail
+				}
+				seen[key] = true
+			}
+
+			select {
+			case jobs <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	workers := im.opts.Concurrency
+	if workers < 1 {
+		workers = im.p.Concurrency
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for r := range jobs {
+				outcomes <- im.process(ctx, r)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	// rows finish out of order, so the checkpoint only moves past a row once
+	// every row before it is done
+	var (
+		done      = map[int]bool{}
+		watermark = resumeAfter
+		saved     = resumeAfter
+		saveErr   error
+	)
+
+	for o := range outcomes {
+		switch o.Status {
+		case StatusCreated:
+			report.Created++
+		case StatusUpdated:
+			report.Updated++
+		case StatusSkipped:
+			report.Skipped++
+			report.Rows = append(report.Rows, o.RowResult)
+		case StatusFailed:
+			report.Failed++
+			report.Rows = append(report.Rows, o.RowResult)
+		}
+
+		if !o.done {
+			continue
+		}
+
+		done[o.Row] = true
+		for done[watermark+1] {
+			delete(done, watermark+1)
+			watermark++
+		}
+
+		if opts := im.opts; opts.Checkpoint != "" && saveErr == nil && watermark-saved >= checkpointInterval {
+			if saveErr = saveCheckpoint(opts.Checkpoint, watermark); saveErr != nil {
+				cancel()
+			}
+			saved = watermark
+		}
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool {
+		return report.Rows[i].Row < report.Rows[j].Row
+	})
+
+	if im.opts.Checkpoint != "" && saveErr == nil && watermark != saved {
+		saveErr = saveCheckpoint(im.opts.Checkpoint, watermark)
+	}
+
+	if readErr != nil {
+		return report, readErr
+	}
+
+	if saveErr != nil {
+		return report, saveErr
+	}
+
+	return report, ctx.Err()
+}
+
+func (im *importer) process(ctx context.Context, r row) outcome {
+	o := outcome{RowResult: RowResult{Row: r.num, Email: r.email}, done: true}
+
+	if r.blank {
+		return o
+	}
+
+	if r.err != nil {
+		o.Status, o.Err = StatusSkipped, r.err
+		return o
+	}
+
+	o.Status, o.Err = im.upsert(ctx, r)
+
+	// rows cut short by cancellation are imported again when resuming
+	if o.Err != nil && ctx.Err() != nil {
+		o.done = false
+	}
+
+	return o
+}
+
+func (im *importer) upsert(ctx context.Context, r row) (Status, error) {
+	id, ok := im.existing[strings.ToLower(r.email)]
+	if !ok {
+		subscribed := im.opts.Subscribed
+		if r.subscribed != nil {
+			subscribed = *r.subscribed
+		}
+
+		_, err := im.p.CreateContactContext(ctx, plunk.CreateContactPayload{
+			Email:      r.email,
+			Subscribed: subscribed,
+			Data:       r.data,
+		})
+		if err == nil {
+			return StatusCreated, nil
+		}
+
+		// created by someone else since the import started
+		if !errors.Is(err, plunk.ErrContactAlreadyExists) {
+			return StatusFailed, err
+		}
+
+		contact, err := im.p.GetContactByEmailContext(ctx, r.email)
+		if err != nil {
+			return StatusFailed, err
+		}
+		id = contact.ID
+	}
+
+	contact, err := im.p.GetContactContext(ctx, id)
+	if err != nil {
+		return StatusFailed, err
+	}
+
+	if contact.Data == nil {
+		contact.Data = map[string]interface{}{}
+	}
+	for k, v := range r.data {
+		contact.Data[k] = v
+	}
+
+	if r.subscribed != nil {
+		contact.Subscribed = *r.subscribed
+	}
+
+	if _, err := im.p.UpdateContactContext(ctx, contact); err != nil {
+		return StatusFailed, err
+	}
+
+	return StatusUpdated, nil
+}
+
+// Lists the contacts that already exist, so that rows can be sorted into
+// creates and updates without looking each email up.
+func existingContacts(ctx context.Context, p *plunk.Plunk) (map[string]string, error) {
+	existing := map[string]string{}
+
+	it := p.ListContactsContext(ctx, plunk.ListOptions{})
+	defer it.Close()
+
+	for it.Next() {
+		existing[strings.ToLower(it.Contact().Email)] = it.Contact().ID
+	}
+
+	return existing, it.Err()
+}
+
+// Checks the address with the client's validator, or the default one, so
+// that rows it would reject are skipped rather than failed.
+func (im *importer) normalizeEmail(email string) (string, error) {
+	if email == "" {
+		return "", plunk.ErrMissingEmail
+	}
+
+	v := im.p.Validator
+	if v == nil {
+		v = &plunk.EmailValidator{}
+	}
+
+	normalized, err := v.Normalize(email)
+	if err != nil {
+		return email, err
+	}
+
+	return normalized, nil
+}
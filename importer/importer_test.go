@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kayode0x/plunk"
+	"github.com/kayode0x/plunk/plunktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T) (*plunk.Plunk, *plunktest.Server) {
+	t.Helper()
+
+	srv := plunktest.NewServer()
+	t.Cleanup(srv.Close)
+
+	p, err := plunk.New(srv.ApiKey, &plunk.Config{BaseUrl: srv.BaseUrl})
+	assert.Nil(t, err)
+
+	return p, srv
+}
+
+func TestImportCSV(t *testing.T) {
+	p, srv := newTestClient(t)
+	srv.AddContact("existing@example.com", false, map[string]interface{}{"plan": "free", "company": "Acme"})
+
+	input := strings.Join([]string{
+		"Email Address,Opted In,Plan,Ignored",
+		"new@example.com,yes,pro,x",
+		"existing@example.com,,team,x",
+		"not-an-email,true,pro,x",
+		",true,pro,x",
+		"third@example.com,maybe,pro,x",
+		"NEW@example.com,no,free,x",
+		"last@example.com,false,,x",
+	}, "\n")
+
+	report, err := Import(context.Background(), p, strings.NewReader(input), Options{
+		Format: FormatCSV,
+		Mapping: Mapping{
+			Email:      "Email Address",
+			Subscribed: "Opted In",
+			Data:       map[string]string{"plan": "Plan"},
+		},
+		Subscribed: true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 4, report.Skipped)
+	assert.Equal(t, 0, report.Failed)
+
+	assert.Len(t, report.Rows, 4)
+	assert.Equal(t, 3, report.Rows[0].Row)
+	assert.True(t, errors.Is(report.Rows[0].Err, ErrInvalidEmail))
+	assert.Equal(t, 4, report.Rows[1].Row)
+	assert.Equal(t, plunk.ErrMissingEmail, report.Rows[1].Err)
+	assert.Equal(t, 5, report.Rows[2].Row)
+	assert.True(t, errors.Is(report.Rows[2].Err, ErrInvalidSubscribed))
+	assert.Equal(t, 6, report.Rows[3].Row)
+	assert.Equal(t, ErrDuplicateEmail, report.Rows[3].Err)
+	for _, r := range report.Rows {
+		assert.Equal(t, StatusSkipped, r.Status)
+	}
+
+	created, _ := srv.Contact("new@example.com")
+	assert.True(t, created.Subscribed)
+	assert.Equal(t, map[string]interface{}{"plan": "pro"}, created.Data)
+
+	// existing contacts keep their subscription status and other data
+	updated, _ := srv.Contact("existing@example.com")
+	assert.False(t, updated.Subscribed)
+	assert.Equal(t, map[string]interface{}{"plan": "team", "company": "Acme"}, updated.Data)
+
+	last, _ := srv.Contact("last@example.com")
+	assert.False(t, last.Subscribed)
+	assert.Nil(t, last.Data)
+}
+
+func TestImportCSVDefaultMapping(t *testing.T) {
+	p, srv := newTestClient(t)
+
+	input := "email,subscribed,first_name,city\nuser@example.com,true,John,\n"
+
+	report, err := Import(context.Background(), p, strings.NewReader(input), Options{Format: FormatCSV})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.Created)
+
+	contact, _ := srv.Contact("user@example.com")
+	assert.True(t, contact.Subscribed)
+	assert.Equal(t, map[string]interface{}{"first_name": "John"}, contact.Data)
+
+	_, err = Import(context.Background(), p, strings.NewReader(input), Options{
+		Format:  FormatCSV,
+		Mapping: Mapping{Email: "Email"},
+	})
+	assert.True(t, errors.Is(err, ErrMissingColumn))
+
+	_, err = Import(context.Background(), p, strings.NewReader(""), Options{Format: FormatCSV})
+	assert.Equal(t, ErrEmptyInput, err)
+
+	_, err = Import(context.Background(), p, strings.NewReader(input), Options{})
+	assert.True(t, errors.Is(err, ErrUnknownFormat))
+}
+
+func TestImportJSONL(t *testing.T) {
+	p, srv := newTestClient(t)
+
+	input := strings.Join([]string{
+		`{"email": "first@example.com", "subscribed": true, "data": {"seats": 12345678901234}}`,
+		``,
+		`{"email": "second@example.com"}`,
+		`{"email": "broken@example.com",`,
+	}, "\n")
+
+	report, err := Import(context.Background(), p, strings.NewReader(input), Options{Format: FormatJSONL})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 4, report.Rows[0].Row)
+	assert.True(t, errors.Is(report.Rows[0].Err, ErrInvalidRow))
+
+	first, _ := srv.Contact("first@example.com")
+	assert.True(t, first.Subscribed)
+	assert.Equal(t, float64(12345678901234), first.Data["seats"])
+
+	second, _ := srv.Contact("second@example.com")
+	assert.False(t, second.Subscribed)
+}
+
+func TestImportFailedRows(t *testing.T) {
+	p, srv := newTestClient(t)
+	srv.Fail("POST /contacts", plunktest.InternalServerError())
+
+	input := "email\nfirst@example.com\nsecond@example.com\n"
+
+	report, err := Import(context.Background(), p, strings.NewReader(input), Options{
+		Format:      FormatCSV,
+		Concurrency: 1,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Rows[0].Row)
+	assert.Equal(t, StatusFailed, report.Rows[0].Status)
+	assert.Equal(t, "Plunk Error (Code: 500, Error: Internal Server Error, Message: Something went wrong)", report.Rows[0].Err.Error())
+}
+
+func TestImportCheckpoint(t *testing.T) {
+	p, srv := newTestClient(t)
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+
+	var b strings.Builder
+	b.WriteString("email\n")
+	for i := 1; i <= 250; i++ {
+		fmt.Fprintf(&b, "user%d@example.com\n", i)
+	}
+	input := b.String()
+
+	// an import that was interrupted after 120 rows
+	assert.Nil(t, saveCheckpoint(path, 120))
+
+	report, err := Import(context.Background(), p, strings.NewReader(input), Options{
+		Format:     FormatCSV,
+		Checkpoint: path,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 120, report.Resumed)
+	assert.Equal(t, 130, report.Created)
+	assert.Len(t, srv.Contacts(), 130)
+
+	_, ok := srv.Contact("user120@example.com")
+	assert.False(t, ok)
+	_, ok = srv.Contact("user121@example.com")
+	assert.True(t, ok)
+
+	row, err := loadCheckpoint(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 250, row)
+
+	// running it again imports nothing
+	report, err = Import(context.Background(), p, strings.NewReader(input), Options{
+		Format:     FormatCSV,
+		Checkpoint: path,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 250, report.Resumed)
+	assert.Equal(t, 0, report.Created)
+}
+
+func TestImportCanceled(t *testing.T) {
+	p, srv := newTestClient(t)
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := Import(ctx, p, strings.NewReader("email\nuser@example.com\n"), Options{
+		Format:     FormatCSV,
+		Checkpoint: path,
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 0, report.Created)
+	assert.Empty(t, srv.Contacts())
+
+	row, err := loadCheckpoint(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, row)
+}
+
+func TestImportFile(t *testing.T) {
+	p, srv := newTestClient(t)
+
+	path := filepath.Join(t.TempDir(), "contacts.jsonl")
+	assert.Nil(t, os.WriteFile(path, []byte(`{"email": "user@example.com"}`+"\n"), 0o644))
+
+	report, err := ImportFile(context.Background(), p, path, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Len(t, srv.Contacts(), 1)
+
+	_, err = ImportFile(context.Background(), p, filepath.Join(t.TempDir(), "missing.csv"), Options{})
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
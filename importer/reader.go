@@ -0,0 +1,213 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A row of the input, numbered from 1. CSV rows are numbered after the header
+// and JSONL rows by line.
+type row struct {
+	num        int
+	email      string
+	subscribed *bool
+	data       map[string]interface{}
+	blank      bool  // an empty JSONL line, which is not imported
+	err        error // why the row cannot be imported
+}
+
+type rowReader interface {
+	// Returns the next row, or io.EOF after the last one. Rows that cannot be
+	// imported are returned with err set; any other error stops the import.
+	next() (row, error)
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	num     int
+	email   int
+	subbed  int            // -1 when there is no subscribed column
+	columns map[string]int // data key -> column
+}
+
+func newCSVReader(r io.Reader, m Mapping) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyInput
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+
+	emailColumn := m.Email
+	if emailColumn == "" {
+		emailColumn = "email"
+	}
+
+	email, ok := index[emailColumn]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrMissingColumn, emailColumn)
+	}
+
+	subbed := -1
+	if m.Subscribed != "" {
+		i, ok := index[m.Subscribed]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrMissingColumn, m.Subscribed)
+		}
+		subbed = i
+	} else if i, ok := index["subscribed"]; ok {
+		subbed = i
+	}
+
+	columns := map[string]int{}
+	if m.Data != nil {
+		for key, column := range m.Data {
+			i, ok := index[column]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrMissingColumn, column)
+			}
+			columns[key] = i
+		}
+	} else {
+		for name, i := range index {
+			if i != email && i != subbed && name != "" {
+				columns[name] = i
+			}
+		}
+	}
+
+	return &csvReader{r: cr, email: email, subbed: subbed, columns: columns}, nil
+}
+
+func (c *csvReader) next() (row, error) {
+	record, err := c.r.Read()
+	if err == io.EOF {
+		return row{}, io.EOF
+	}
+
+	c.num++
+	r := row{num: c.num}
+
+	if err != nil {
+		// a row with the wrong number of fields is skipped, anything else
+		// means the rest of the file cannot be trusted
+		if errors.Is(err, csv.ErrFieldCount) {
+			r.err = err
+			return r, nil
+		}
+
+		return row{}, err
+	}
+
+	r.email = strings.TrimSpace(record[c.email])
+
+	if c.subbed >= 0 {
+		if value := strings.TrimSpace(record[c.subbed]); value != "" {
+			subscribed, err := parseBool(value)
+			if err != nil {
+				r.err = err
+				return r, nil
+			}
+			r.subscribed = &subscribed
+		}
+	}
+
+	for key, i := range c.columns {
+		if value := record[i]; value != "" {
+			if r.data == nil {
+				r.data = map[string]interface{}{}
+			}
+			r.data[key] = value
+		}
+	}
+
+	return r, nil
+}
+
+type jsonlReader struct {
+	s   *bufio.Scanner
+	num int
+}
+
+// One JSONL line, in the same shape as plunk.CreateContactPayload.
+type jsonlRow struct {
+	Email      string                 `json:"email"`
+	Subscribed *bool                  `json:"subscribed"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// The longest JSONL line the importer accepts.
+const maxLineSize = 1 << 20
+
+func newJSONLReader(r io.Reader) *jsonlReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	return &jsonlReader{s: s}
+}
+
+func (j *jsonlReader) next() (row, error) {
+	if !j.s.Scan() {
+		if err := j.s.Err(); err != nil {
+			return row{}, err
+		}
+
+		return row{}, io.EOF
+	}
+
+	j.num++
+	r := row{num: j.num}
+
+	line := bytes.TrimSpace(j.s.Bytes())
+	if len(line) == 0 {
+		r.blank = true
+		return r, nil
+	}
+
+	var v jsonlRow
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		r.err = fmt.Errorf("%w: %s", ErrInvalidRow, err.Error())
+		return r, nil
+	}
+
+	r.email = strings.TrimSpace(v.Email)
+	r.subscribed = v.Subscribed
+	r.data = v.Data
+
+	return r, nil
+}
+
+// Like strconv.ParseBool, but also accepts yes/no and y/n in any case.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y":
+		return true, nil
+	case "no", "n":
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %q", ErrInvalidSubscribed, value)
+	}
+
+	return b, nil
+}
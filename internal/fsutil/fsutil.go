@@ -0,0 +1,21 @@
+// Package fsutil holds the file system helpers shared by the client and its
+// subpackages.
+package fsutil
+
+import "os"
+
+// SyncDir syncs the directory at path, which makes the files created, renamed
+// or removed in it durable.
+func SyncDir(path string) error {
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+
+	return d.Close()
+}
@@ -0,0 +1,316 @@
+//go:build live
+
+// Tests that run against the real Plunk API. They need PLUNK_SECRET_KEY in a
+// .env file and are only built with: go test -tags live -run Live
+package plunk
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+var opts = &Config{
+	Debug: true,
+}
+
+func getEnvVariable(key string) string {
+	err := godotenv.Load(".env")
+
+	if err != nil {
+		log.Fatalf("Error loading .env file")
+	}
+
+	return os.Getenv(key)
+}
+
+var secretKey = getEnvVariable("PLUNK_SECRET_KEY")
+
+func TestLiveGetContact(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	payload := CreateContactPayload{
+		Email: testEmail,
+	}
+
+	contact, err := p.CreateContact(payload)
+	assert.Nil(t, err)
+	assert.NotNil(t, contact)
+
+	contact, err = p.GetContact(contact.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, contact)
+
+	_, err = p.DeleteContact(contact.ID)
+	assert.Nil(t, err)
+}
+
+func TestLiveGetContacts(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	contacts, err := p.GetContacts()
+	assert.Nil(t, err)
+	assert.NotNil(t, contacts)
+}
+
+func TestLiveGetContactsCount(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	count, err := p.GetContactsCount()
+	assert.Nil(t, err)
+	assert.NotNil(t, count)
+}
+
+func TestLiveCreateContact(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	data := map[string]interface{}{
+		"first_name": "John",
+		"last_name":  "Doe",
+	}
+
+	payload := CreateContactPayload{
+		Data:       data,
+		Subscribed: true,
+		Email:      testEmail,
+	}
+
+	contact, err := p.CreateContact(payload)
+	assert.Nil(t, err)
+	assert.NotNil(t, contact)
+	assert.Equal(t, contact.Email, testEmail)
+	assert.Equal(t, contact.Subscribed, true)
+
+	err = contact.ParseData()
+	assert.Nil(t, err)
+	assert.Equal(t, contact.Data["first_name"], "John")
+	assert.Equal(t, contact.Data["last_name"], "Doe")
+
+	_, err = p.DeleteContact(contact.ID)
+	assert.Nil(t, err)
+}
+
+func TestLiveUpdateContact(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	data := map[string]interface{}{
+		"first_name": "John",
+		"last_name":  "Doe",
+	}
+	payload := CreateContactPayload{
+		Data:       data,
+		Subscribed: true,
+		Email:      testEmail,
+	}
+
+	contact, err := p.CreateContact(payload)
+	assert.Nil(t, err)
+	assert.NotNil(t, contact)
+
+	err = contact.ParseData()
+	assert.Nil(t, err)
+
+	newEmail := "user2@example.com"
+	newData := map[string]interface{}{
+		"first_name": "Jane",
+		"last_name":  "Domingo",
+	}
+
+	newContactData := &Contact{
+		ID:         contact.ID,
+		Email:      newEmail,
+		Data:       newData,
+		Subscribed: false,
+	}
+
+	newContact, err := p.UpdateContact(newContactData)
+	assert.Nil(t, err)
+	assert.NotNil(t, newContact)
+	assert.Equal(t, newContact.Email, newEmail)
+	assert.Equal(t, newContact.Subscribed, false)
+
+	err = newContact.ParseData()
+	assert.Nil(t, err)
+	assert.Equal(t, newContact.Data["first_name"], "Jane")
+	assert.Equal(t, newContact.Data["last_name"], "Domingo")
+
+	_, err = p.DeleteContact(newContact.ID)
+	assert.Nil(t, err)
+}
+
+func TestLiveDeleteContact(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	payload := CreateContactPayload{
+		Email: testEmail,
+	}
+
+	contact, err := p.CreateContact(payload)
+	assert.Nil(t, err)
+	assert.NotNil(t, contact)
+
+	_, err = p.DeleteContact(contact.ID)
+	assert.Nil(t, err)
+
+	_, err = p.DeleteContact(contact.ID)
+	assert.NotNil(t, err)
+
+	expectedErr := "Plunk Error (Code: 404, Error: Not Found, Message: That contact was not found)"
+	assert.Equal(t, err.Error(), expectedErr)
+}
+
+func TestLiveSubOrUnsubscribeContact(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	payload := CreateContactPayload{
+		Email: testEmail,
+	}
+
+	contact, err := p.CreateContact(payload)
+	assert.Nil(t, err)
+	assert.NotNil(t, contact)
+
+	tests := []struct {
+		name     string
+		email    string
+		sub      bool
+		expected bool
+	}{
+		{
+			name:     "unsubscribe",
+			sub:      true,
+			expected: false,
+		},
+		{
+			name:     "subscribe",
+			sub:      false,
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		newContact, err := p.subOrUnsubscribeContact(context.Background(), contact.ID, test.sub)
+		assert.Nil(t, err)
+		assert.NotNil(t, newContact)
+		assert.Equal(t, newContact.Email, testEmail)
+		assert.Equal(t, newContact.Subscribed, test.expected)
+	}
+
+	_, err = p.DeleteContact(contact.ID)
+	assert.Nil(t, err)
+}
+
+func TestLiveTriggerEvent(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	payload := EventPayload{
+		Event: testEvent,
+		Email: eventTestEmail,
+	}
+
+	resp, err := p.TriggerEvent(payload)
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+
+	_, err = p.DeleteEvent(resp.Event)
+	assert.Nil(t, err)
+}
+
+func TestLiveDeleteEvent(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	payload := EventPayload{
+		Event: testEvent,
+		Email: eventTestEmail,
+	}
+
+	resp, err := p.TriggerEvent(payload)
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	id := resp.Event
+
+	event, err := p.DeleteEvent(resp.Event)
+	assert.Nil(t, err)
+	assert.NotNil(t, event)
+	assert.Equal(t, id, event.ID)
+}
+
+func TestLiveSendTransactionalEmail(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	payload := TransactionalEmailPayload{
+		To:      []string{"test@example.com"},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	}
+
+	res, err := p.SendTransactionalEmail(payload)
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, res.Success, true)
+}
+
+func TestLiveSendMultipleTransactionalEmails(t *testing.T) {
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	payload := []TransactionalEmailPayload{
+		{
+			To:      []string{"test@example.com"},
+			Subject: "Test Subject",
+			Body:    "Test Body",
+		},
+		{
+			To:      []string{"test@example.com"},
+			Subject: "Test Subject 2",
+			Body:    "# Test Body 2",
+		},
+	}
+
+	res, err := p.SendMultipleTransactionalEmails(payload)
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, res[0].Success, true)
+	assert.Equal(t, res[1].Success, true)
+
+	for _, r := range res {
+		assert.Equal(t, r.Success, true)
+	}
+}
+
+func TestLiveSendRequest(t *testing.T) {
+	// create a new Plunk object with a mocked http.Client
+	p, err := New(secretKey, opts)
+	assert.Nil(t, err)
+
+	// create a SendConfig object with a GET method and a mocked response body
+	config := SendConfig{
+		Url:    p.url(contactsCountEndpoint),
+		Method: http.MethodGet,
+		Body:   nil,
+	}
+
+	resp, err := p.sendRequest(context.Background(), config)
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+
+	var body interface{}
+	err = decodeResponse(resp, &body)
+	assert.Nil(t, err)
+	assert.NotNil(t, body)
+}
@@ -0,0 +1,282 @@
+package plunk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// LocalTemplate is the source of an email rendered by the client with Go's
+// text/template and html/template, rather than stored in Plunk. Executing any
+// of them with a map that lacks a key they reference is an error.
+type LocalTemplate struct {
+	Subject string // text/template, rendered on a single line
+	HTML    string // html/template, sent as the body
+	Text    string // text/template; when empty, the text is derived from HTML
+
+	// Functions available to all three templates.
+	Funcs map[string]interface{}
+}
+
+// RenderedEmail is a LocalTemplate executed with some data.
+type RenderedEmail struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+var (
+	ErrMissingTemplateName = errors.New("missing template name")
+	ErrUnknownTemplate     = errors.New("unknown local template")
+)
+
+type localTemplate struct {
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template // nil when derived from html
+}
+
+// LocalTemplates is a registry of named LocalTemplates, safe for concurrent
+// use. New sets one as Config.LocalTemplates unless one is given. The zero
+// value is ready to use.
+type LocalTemplates struct {
+	mu        sync.RWMutex
+	templates map[string]*localTemplate
+}
+
+func NewLocalTemplates() *LocalTemplates {
+	return &LocalTemplates{templates: map[string]*localTemplate{}}
+}
+
+// Parses tmpl and registers it as name, replacing any template registered
+// under that name before.
+func (t *LocalTemplates) Register(name string, tmpl LocalTemplate) error {
+	if name == "" {
+		return ErrMissingTemplateName
+	}
+
+	if tmpl.Subject == "" {
+		return ErrMissingSubject
+	}
+
+	if tmpl.HTML == "" {
+		return ErrMissingBody
+	}
+
+	parsed := &localTemplate{}
+
+	var err error
+	if parsed.subject, err = texttemplate.New(name + ".subject").Funcs(tmpl.Funcs).Option("missingkey=error").Parse(tmpl.Subject); err != nil {
+		return err
+	}
+
+	if parsed.html, err = htmltemplate.New(name + ".html").Funcs(tmpl.Funcs).Option("missingkey=error").Parse(tmpl.HTML); err != nil {
+		return err
+	}
+
+	if tmpl.Text != "" {
+		if parsed.text, err = texttemplate.New(name + ".text").Funcs(tmpl.Funcs).Option("missingkey=error").Parse(tmpl.Text); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.templates == nil {
+		t.templates = map[string]*localTemplate{}
+	}
+
+	t.templates[name] = parsed
+
+	return nil
+}
+
+// Executes the template registered as name with data.
+func (t *LocalTemplates) Render(name string, data interface{}) (*RenderedEmail, error) {
+	var tmpl *localTemplate
+	if t != nil {
+		t.mu.RLock()
+		tmpl = t.templates[name]
+		t.mu.RUnlock()
+	}
+
+	if tmpl == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTemplate, name)
+	}
+
+	var subject, body, text bytes.Buffer
+
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.html.Execute(&body, data); err != nil {
+		return nil, err
+	}
+
+	if tmpl.text != nil {
+		if err := tmpl.text.Execute(&text, data); err != nil {
+			return nil, err
+		}
+	} else {
+		text.WriteString(htmlToText(body.String()))
+	}
+
+	return &RenderedEmail{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		HTML:    body.String(),
+		Text:    strings.TrimSpace(text.String()),
+	}, nil
+}
+
+// Registers a template with the client's LocalTemplates. See
+// LocalTemplates.Register.
+func (p *Plunk) RegisterTemplate(name string, tmpl LocalTemplate) error {
+	return p.LocalTemplates.Register(name, tmpl)
+}
+
+// Renders a template registered with RegisterTemplate, without sending it.
+func (p *Plunk) RenderTemplate(name string, data interface{}) (*RenderedEmail, error) {
+	return p.LocalTemplates.Render(name, data)
+}
+
+// Renders the template registered as name with data and sends the result to
+// every recipient in to. Nothing is sent when rendering fails. To set the
+// sender or attachments, render with RenderTemplate and send the payload
+// yourself.
+func (p *Plunk) SendTemplate(ctx context.Context, name string, to Recipients, data interface{}) (*TransactionalEmailResponse, error) {
+	email, err := p.RenderTemplate(name, data)
+	if err != nil {
+		p.logError("could not render template", "template", name, "error", err)
+		return nil, err
+	}
+
+	return p.SendTransactionalEmailContext(ctx, TransactionalEmailPayload{
+		To:      to,
+		Subject: email.Subject,
+		Body:    email.HTML,
+		Text:    email.Text,
+
+		BodyFormat: BodyFormatHTML,
+	})
+}
+
+// Elements whose content is not part of the text.
+var hiddenElements = map[string]bool{"head": true, "script": true, "style": true, "title": true}
+
+// Elements that start a new paragraph, or a new line.
+var (
+	paragraphElements = map[string]bool{
+		"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"ul": true, "ol": true, "table": true, "blockquote": true, "pre": true,
+	}
+	lineElements = map[string]bool{"br": true, "div": true, "tr": true, "hr": true}
+)
+
+// Converts rendered HTML to plain text for the fallback body: tags are
+// dropped, blocks are separated by blank lines, list items are prefixed with
+// "- " and links are followed by their URL.
+func htmlToText(s string) string {
+	var (
+		b      strings.Builder
+		hidden int
+		href   string
+		start  int // where the text of the current link starts in b
+	)
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		name, _ := z.TagName()
+		tag := string(name)
+
+		switch tt {
+		case html.TextToken:
+			if hidden == 0 {
+				// newlines in HTML source are spaces on screen
+				b.WriteString(strings.Map(func(r rune) rune {
+					if unicode.IsSpace(r) {
+						return ' '
+					}
+					return r
+				}, string(z.Text())))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch {
+			case hiddenElements[tag]:
+				if tt == html.StartTagToken {
+					hidden++
+				}
+			case paragraphElements[tag]:
+				b.WriteString("\n\n")
+			case lineElements[tag]:
+				b.WriteString("\n")
+			case tag == "li":
+				b.WriteString("\n- ")
+			case tag == "td" || tag == "th":
+				b.WriteString(" ")
+			case tag == "a":
+				href, start = "", b.Len()
+				for {
+					key, value, more := z.TagAttr()
+					if string(key) == "href" {
+						href = string(value)
+					}
+					if !more {
+						break
+					}
+				}
+			}
+
+		case html.EndTagToken:
+			switch {
+			case hiddenElements[tag]:
+				if hidden > 0 {
+					hidden--
+				}
+			case paragraphElements[tag]:
+				b.WriteString("\n\n")
+			case tag == "div" || tag == "tr":
+				b.WriteString("\n")
+			case tag == "a":
+				if href != "" && strings.TrimSpace(b.String()[start:]) != href {
+					b.WriteString(" (" + href + ")")
+				}
+				href = ""
+			}
+		}
+	}
+
+	// collapse the spaces within lines, and the blank lines between them
+	lines := []string{}
+	blank := true
+	for _, line := range strings.Split(b.String(), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+
+		lines = append(lines, line)
+		blank = false
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
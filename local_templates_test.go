@@ -0,0 +1,140 @@
+package plunk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var passwordReset = LocalTemplate{
+	Subject: "Reset your {{.Product}} password",
+	HTML: `<html><head><title>Reset</title><style>p { color: red; }</style></head><body>
+<h1>Hi {{.Name}},</h1>
+<p>Someone asked to reset the password of your   {{.Product}}
+account.</p>
+<p><a href="{{.URL}}">Reset your password</a></p>
+<ul><li>Expires in {{.Hours}} hours</li><li>Used once</li></ul>
+</body></html>`,
+}
+
+func TestRenderTemplate(t *testing.T) {
+	p, _ := newTestClient(t)
+	assert.Nil(t, p.RegisterTemplate("password-reset", passwordReset))
+
+	email, err := p.RenderTemplate("password-reset", map[string]interface{}{
+		"Product": "Acme",
+		"Name":    "<Ada>",
+		"URL":     "https://example.com/reset?token=abc&user=1",
+		"Hours":   2,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Reset your Acme password", email.Subject)
+	assert.Contains(t, email.HTML, "<h1>Hi &lt;Ada&gt;,</h1>")
+	assert.Contains(t, email.HTML, `href="https://example.com/reset?token=abc&amp;user=1"`)
+
+	assert.Equal(t, strings.Join([]string{
+		"Hi <Ada>,",
+		"",
+		"Someone asked to reset the password of your Acme account.",
+		"",
+		"Reset your password (https://example.com/reset?token=abc&user=1)",
+		"",
+		"- Expires in 2 hours",
+		"- Used once",
+	}, "\n"), email.Text)
+}
+
+func TestRenderTemplateText(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	type data struct{ Name string }
+
+	assert.Nil(t, p.RegisterTemplate("welcome", LocalTemplate{
+		Subject: "Welcome,\n{{shout .Name}}",
+		HTML:    "<p>Welcome, {{shout .Name}}</p>",
+		Text:    "Welcome, {{shout .Name}}\n",
+		Funcs:   map[string]interface{}{"shout": strings.ToUpper},
+	}))
+
+	email, err := p.RenderTemplate("welcome", data{Name: "Ada"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Welcome, ADA", email.Subject)
+	assert.Equal(t, "<p>Welcome, ADA</p>", email.HTML)
+	assert.Equal(t, "Welcome, ADA", email.Text)
+}
+
+func TestRenderTemplateErrors(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	assert.Equal(t, ErrMissingTemplateName, p.RegisterTemplate("", passwordReset))
+	assert.Equal(t, ErrMissingSubject, p.RegisterTemplate("empty", LocalTemplate{HTML: "<p>Hi</p>"}))
+	assert.Equal(t, ErrMissingBody, p.RegisterTemplate("empty", LocalTemplate{Subject: "Hi"}))
+	assert.NotNil(t, p.RegisterTemplate("broken", LocalTemplate{Subject: "Hi", HTML: "<p>{{.Name</p>"}))
+
+	_, err := p.RenderTemplate("password-reset", nil)
+	assert.True(t, errors.Is(err, ErrUnknownTemplate))
+
+	assert.Nil(t, p.RegisterTemplate("password-reset", passwordReset))
+
+	_, err = p.RenderTemplate("password-reset", map[string]interface{}{"Product": "Acme"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `map has no entry for key "Name"`)
+}
+
+func TestSendTemplate(t *testing.T) {
+	p, server := newTestClient(t)
+	assert.Nil(t, p.RegisterTemplate("password-reset", passwordReset))
+
+	res, err := p.SendTemplate(context.Background(), "password-reset", []string{"ada@example.com", "grace@example.com"}, map[string]interface{}{
+		"Product": "Acme",
+		"Name":    "Ada",
+		"URL":     "https://example.com/reset",
+		"Hours":   2,
+	})
+	assert.Nil(t, err)
+	assert.True(t, res.Success)
+
+	emails := server.Emails()
+	assert.Len(t, emails, 2)
+	assert.Equal(t, "grace@example.com", emails[1].To)
+	assert.Equal(t, "Reset your Acme password", emails[0].Subject)
+	assert.Contains(t, emails[0].Body, "<h1>Hi Ada,</h1>")
+	assert.True(t, strings.HasPrefix(emails[0].Text, "Hi Ada,\n\n"))
+
+	// nothing is sent when a variable is missing
+	_, err = p.SendTemplate(context.Background(), "password-reset", []string{"ada@example.com"}, map[string]interface{}{"Name": "Ada"})
+	assert.NotNil(t, err)
+	assert.Len(t, server.Emails(), 2)
+}
+
+func TestSharedLocalTemplates(t *testing.T) {
+	templates := NewLocalTemplates()
+	assert.Nil(t, templates.Register("password-reset", passwordReset))
+
+	p, err := New("api-key", &Config{LocalTemplates: templates})
+	assert.Nil(t, err)
+	assert.Equal(t, templates, p.LocalTemplates)
+
+	_, err = p.RenderTemplate("password-reset", nil)
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrUnknownTemplate))
+}
+
+func TestLocalTemplatesZeroValue(t *testing.T) {
+	p, err := New("api-key", &Config{LocalTemplates: &LocalTemplates{}})
+	assert.Nil(t, err)
+
+	_, err = p.RenderTemplate("password-reset", nil)
+	assert.True(t, errors.Is(err, ErrUnknownTemplate))
+
+	assert.Nil(t, p.RegisterTemplate("password-reset", passwordReset))
+
+	email, err := p.RenderTemplate("password-reset", map[string]interface{}{
+		"Product": "Acme", "Name": "Ada", "URL": "https://example.com", "Hours": 2,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Reset your Acme password", email.Subject)
+}
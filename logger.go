@@ -0,0 +1,154 @@
+package plunk
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Logger receives the client's logs as a message followed by alternating
+// keys and values, in the style of log/slog. A *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// NewPrintLogger returns a Logger that writes one line per entry to w, e.g.
+//
+//	[INFO] request succeeded method=POST path=/v1/send status=200
+func NewPrintLogger(w io.Writer) Logger {
+	return &printLogger{w: w}
+}
+
+type printLogger struct {
+	w io.Writer
+}
+
+func (l *printLogger) Debug(msg string, keyvals ...interface{}) {
+	l.print("DEBUG", msg, keyvals)
+}
+
+func (l *printLogger) Info(msg string, keyvals ...interface{}) {
+	l.print("INFO", msg, keyvals)
+}
+
+func (l *printLogger) Error(msg string, keyvals ...interface{}) {
+	l.print("ERROR", msg, keyvals)
+}
+
+func (l *printLogger) print(level, msg string, keyvals []interface{}) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", level, msg)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		var value interface{} = "MISSING"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+
+		str := fmt.Sprint(value)
+		if strings.ContainsAny(str, " \t\n\"=") {
+			str = fmt.Sprintf("%q", str)
+		}
+
+		fmt.Fprintf(&b, " %v=%s", keyvals[i], str)
+	}
+
+	fmt.Fprintln(l.w, b.String())
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keyvals ...interface{}) {}
+func (nopLogger) Info(msg string, keyvals ...interface{})  {}
+func (nopLogger) Error(msg string, keyvals ...interface{}) {}
+
+// Log returns the Logger the client writes to: Config.Logger, or stdout in
+// debug mode, or one that discards every entry. Packages built on the
+// client, such as outbox, log through it too.
+func (p *Plunk) Log() Logger {
+	if p.Logger != nil {
+		return p.Logger
+	}
+
+	if p.Debug {
+		return NewPrintLogger(os.Stdout)
+	}
+
+	return nopLogger{}
+}
+
+func (p *Plunk) logDebug(msg string, keyvals ...interface{}) {
+	p.Log().Debug(msg, keyvals...)
+}
+
+func (p *Plunk) logInfo(msg string, keyvals ...interface{}) {
+	p.Log().Info(msg, keyvals...)
+}
+
+func (p *Plunk) logError(msg string, keyvals ...interface{}) {
+	p.Log().Error(msg, keyvals...)
+}
+
+// Fields of a request body that are replaced before the body is logged.
+var sensitiveFields = map[string]bool{
+	"body":        true,
+	"text":        true,
+	"attachments": true,
+	"data":        true, // contact and event data, often personal details
+	"headers":     true, // may carry tokens, e.g. in List-Unsubscribe links
+}
+
+const redacted = "[REDACTED]"
+
+// Returns the request body as it should be logged. Email bodies, attachments,
+// custom headers and contact or event data are redacted unless
+// Config.LogSensitive is set.
+func (p *Plunk) redact(body []byte) string {
+	if p.LogSensitive {
+		return string(body)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		// not an object, so there are no named fields to keep
+		return redacted
+	}
+
+	if fields == nil {
+		return ""
+	}
+
+	for key := range fields {
+		if sensitiveFields[key] {
+			fields[key] = redacted
+		}
+	}
+
+	b, _ := json.Marshal(fields)
+	return string(b)
+}
+
+// Returns the Type of a Plunk API error, or "" for any other error.
+func errorType(err error) string {
+	var plunkErr *CustomError
+	if errors.As(err, &plunkErr) {
+		return plunkErr.Type
+	}
+
+	return ""
+}
+
+// Returns a random ID that ties together the log entries of one request.
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
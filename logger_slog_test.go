@@ -0,0 +1,42 @@
+//go:build go1.21
+
+package plunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/kayode0x/plunk/plunktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	server := plunktest.NewServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	p, err := New(server.ApiKey, &Config{BaseUrl: server.BaseUrl, Logger: logger})
+	assert.Nil(t, err)
+
+	_, err = p.GetContactsCount()
+	assert.Nil(t, err)
+
+	var entry map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		json.Unmarshal([]byte(line), &entry)
+		if entry["msg"] == "request succeeded" {
+			break
+		}
+	}
+
+	assert.Equal(t, "request succeeded", entry["msg"])
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/contacts/count", entry["path"])
+	assert.Equal(t, float64(200), entry["status"])
+}
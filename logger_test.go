@@ -0,0 +1,182 @@
+package plunk
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kayode0x/plunk/plunktest"
+	"github.com/stretchr/testify/assert"
+)
+
+type logEntry struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) record(level, msg string, keyvals []interface{}) {
+	fields := map[string]interface{}{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fields[keyvals[i].(string)] = keyvals[i+1]
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, fields: fields})
+}
+
+func (l *recordingLogger) Debug(msg string, keyvals ...interface{}) { l.record("DEBUG", msg, keyvals) }
+func (l *recordingLogger) Info(msg string, keyvals ...interface{})  { l.record("INFO", msg, keyvals) }
+func (l *recordingLogger) Error(msg string, keyvals ...interface{}) { l.record("ERROR", msg, keyvals) }
+
+func (l *recordingLogger) find(msg string) []logEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	found := []logEntry{}
+	for _, e := range l.entries {
+		if e.msg == msg {
+			found = append(found, e)
+		}
+	}
+
+	return found
+}
+
+func (l *recordingLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return fmt.Sprint(l.entries)
+}
+
+func TestLoggerRequestFields(t *testing.T) {
+	server := plunktest.NewServer()
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	p, err := New(server.ApiKey, &Config{
+		BaseUrl: server.BaseUrl,
+		Logger:  logger,
+		Retry:   testRetryPolicy(),
+	})
+	assert.Nil(t, err)
+
+	server.Fail("GET /contacts/count", plunktest.InternalServerError())
+
+	_, err = p.GetContactsCount()
+	assert.Nil(t, err)
+
+	attempts := logger.find("request attempt")
+	assert.Len(t, attempts, 2)
+	assert.Equal(t, 1, attempts[0].fields["attempt"])
+	assert.Equal(t, 500, attempts[0].fields["status"])
+	assert.Equal(t, 2, attempts[1].fields["attempt"])
+	assert.Equal(t, 200, attempts[1].fields["status"])
+
+	succeeded := logger.find("request succeeded")
+	assert.Len(t, succeeded, 1)
+
+	fields := succeeded[0].fields
+	assert.Equal(t, "GET", fields["method"])
+	assert.Equal(t, "/contacts/count", fields["path"])
+	assert.Equal(t, 200, fields["status"])
+	assert.Equal(t, 2, fields["attempts"])
+	assert.IsType(t, time.Duration(0), fields["latency"])
+	assert.NotEmpty(t, fields["request_id"])
+	assert.Equal(t, attempts[0].fields["request_id"], fields["request_id"])
+
+	server.Fail("DELETE /contacts", plunktest.Failure{Status: 404, Message: "That contact was not found"})
+
+	_, err = p.DeleteContact("123")
+	assert.NotNil(t, err)
+
+	failed := logger.find("request failed")
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "ERROR", failed[0].level)
+	assert.Equal(t, 404, failed[0].fields["status"])
+	assert.Equal(t, "Not Found", failed[0].fields["error_type"])
+}
+
+func TestLoggerRedactsSensitiveData(t *testing.T) {
+	server := plunktest.NewServer()
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	p, err := New(server.ApiKey, &Config{BaseUrl: server.BaseUrl, Logger: logger})
+	assert.Nil(t, err)
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:      []string{"test@example.com"},
+		Subject: "Your reset link",
+		Body:    "https://example.com/reset?token=secret",
+	})
+	assert.Nil(t, err)
+
+	sent := logger.find("sending request")
+	assert.Len(t, sent, 1)
+	assert.Contains(t, sent[0].fields["body"], `"body":"[REDACTED]"`)
+	assert.Contains(t, sent[0].fields["body"], `"subject":"Your reset link"`)
+
+	logs := logger.String()
+	assert.NotContains(t, logs, "token=secret")
+	assert.NotContains(t, logs, server.ApiKey)
+
+	// opting out logs the body verbatim
+	p.LogSensitive = true
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:      []string{"test@example.com"},
+		Subject: "Your reset link",
+		Body:    "https://example.com/reset?token=secret",
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, logger.String(), "token=secret")
+}
+
+func TestRedact(t *testing.T) {
+	p, err := New("test-api-key", nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, `{"body":"[REDACTED]","to":"a@example.com"}`, p.redact([]byte(`{"to":"a@example.com","body":"hello"}`)))
+	assert.Equal(t, `{"id":"123"}`, p.redact([]byte(`{"id":"123"}`)))
+	assert.Equal(t,
+		`{"data":"[REDACTED]","email":"a@example.com","headers":"[REDACTED]"}`,
+		p.redact([]byte(`{"email":"a@example.com","data":{"phone":"555"},"headers":{"X-Token":"secret"}}`)),
+	)
+	assert.Equal(t, "", p.redact([]byte(`null`)))
+	assert.Equal(t, redacted, p.redact([]byte(`["hello"]`)))
+}
+
+func TestPrintLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewPrintLogger(&buf)
+
+	logger.Info("request succeeded", "method", "GET", "status", 200)
+	logger.Error("request failed", "error", "Plunk Error (Code: 404)", "dangling")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "[INFO] request succeeded method=GET status=200", lines[0])
+	assert.Equal(t, `[ERROR] request failed error="Plunk Error (Code: 404)" dangling=MISSING`, lines[1])
+}
+
+func TestDebugLogsToStdout(t *testing.T) {
+	p, err := New("test-api-key", nil)
+	assert.Nil(t, err)
+	assert.IsType(t, nopLogger{}, p.Log())
+
+	p.Debug = true
+	assert.IsType(t, &printLogger{}, p.Log())
+
+	logger := &recordingLogger{}
+	p.Logger = logger
+	assert.Equal(t, logger, p.Log())
+}
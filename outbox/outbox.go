@@ -0,0 +1,522 @@
+// Package outbox queues transactional emails durably and sends them in the
+// background, so that they survive process restarts and Plunk outages.
+//
+// Enqueue persists an email and returns at once, e.g. from an HTTP handler.
+// Run drains the queue through the client, retrying failed emails with
+// backoff until they are sent or run out of attempts, after which they are
+// dead-lettered for a person to look at.
+//
+//	store, _ := outbox.OpenLogStore("outbox.log")
+//	defer store.Close()
+//
+//	o := outbox.New(p, store, outbox.Options{})
+//	go o.Run(ctx)
+//
+//	id, err := o.Enqueue(payload)
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kayode0x/plunk"
+)
+
+// Status is where a message is in the outbox.
+type Status string
+
+const (
+	StatusPending Status = "pending" // waiting to be sent, or to be retried
+	StatusSent    Status = "sent"
+	StatusDead    Status = "dead" // failed permanently, or ran out of attempts
+)
+
+// Message is an email in the outbox.
+type Message struct {
+	ID      string                          `json:"id"`
+	Payload plunk.TransactionalEmailPayload `json:"payload"`
+	Status  Status                          `json:"status"`
+
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"lastError,omitempty"` // error of the last failed attempt
+	EnqueuedAt  time.Time `json:"enqueuedAt"`
+	NextAttempt time.Time `json:"nextAttempt"` // when a pending message is sent next
+	SentAt      time.Time `json:"sentAt"`      // zero until the message is sent
+
+	Response *plunk.TransactionalEmailResponse `json:"response,omitempty"` // set once sent
+}
+
+// The fields of a payload that are not part of the API request, and would be
+// lost in JSON otherwise.
+type storedPayload struct {
+	plunk.TransactionalEmailPayload
+	Template       string           `json:"template,omitempty"`
+	BodyFormat     plunk.BodyFormat `json:"bodyFormat,omitempty"`
+	IdempotencyKey string           `json:"idempotencyKey,omitempty"`
+}
+
+// The JSON form of a Message. The payload is left out of the lines of a
+// LogStore that only record a change of status.
+type message Message
+
+type messageJSON struct {
+	message
+	Payload *storedPayload `json:"payload,omitempty"`
+}
+
+func (m Message) MarshalJSON() ([]byte, error) {
+	return m.marshal(true)
+}
+
+func (m Message) marshal(withPayload bool) ([]byte, error) {
+	v := messageJSON{message: message(m)}
+	if withPayload {
+		pl := m.Payload
+		v.Payload = &storedPayload{pl, pl.Template, pl.BodyFormat, pl.IdempotencyKey}
+	}
+
+	return json.Marshal(v)
+}
+
+func (m *Message) UnmarshalJSON(b []byte) error {
+	_, err := m.unmarshal(b)
+	return err
+}
+
+// Like UnmarshalJSON, but also reports whether b holds the payload.
+func (m *Message) unmarshal(b []byte) (bool, error) {
+	var v messageJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return false, err
+	}
+
+	*m = Message(v.message)
+	if v.Payload == nil {
+		return false, nil
+	}
+
+	m.Payload = v.Payload.TransactionalEmailPayload
+	m.Payload.Template = v.Payload.Template
+	m.Payload.BodyFormat = v.Payload.BodyFormat
+	m.Payload.IdempotencyKey = v.Payload.IdempotencyKey
+
+	return true, nil
+}
+
+// Options configures an outbox.
+type Options struct {
+	// Number of emails sent at once. Defaults to the client's Concurrency.
+	Concurrency int
+
+	// Attempts before a message is dead-lettered. Defaults to 10.
+	MaxAttempts int
+
+	// Delay before the given attempt, counted from 2. Defaults to doubling
+	// from 1 second up to 1 hour.
+	Backoff func(attempt int) time.Duration
+
+	// How often the store is checked for messages that are due. Enqueue
+	// wakes the outbox up right away. Defaults to 1 second.
+	PollInterval time.Duration
+}
+
+var (
+	ErrNotFound   = errors.New("message not found")
+	ErrNotDead    = errors.New("message is not dead")
+	ErrCorruptLog = errors.New("corrupt outbox log")
+)
+
+const (
+	defaultMaxAttempts  = 10
+	defaultPollInterval = time.Second
+	maxBackoff          = time.Hour
+)
+
+// Outbox queues emails in a Store and sends them through a Plunk client.
+type Outbox struct {
+	p     *plunk.Plunk
+	store Store
+	opts  Options
+
+	wake chan struct{}
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+	storeErr error // why the outcome of an email could not be recorded
+}
+
+// Returns an outbox that sends the messages of store through p. Call Run to
+// start sending.
+func New(p *plunk.Plunk, store Store, opts Options) *Outbox {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = p.Concurrency
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+
+	if opts.Backoff == nil {
+		opts.Backoff = defaultBackoff
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+
+	return &Outbox{
+		p:        p,
+		store:    store,
+		opts:     opts,
+		wake:     make(chan struct{}, 1),
+		inFlight: map[string]bool{},
+	}
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	if attempt > 13 {
+		return maxBackoff
+	}
+
+	d := time.Second << (attempt - 2)
+	if d > maxBackoff {
+		return maxBackoff
+	}
+
+	return d
+}
+
+// Persists payload and returns the ID of its message. The email is sent by
+// Run. Payloads without an IdempotencyKey are given the message ID, so that a
+// client with an IdempotencyStore that survives restarts returns the first
+// response when an email is sent again, e.g. after a crash.
+func (o *Outbox) Enqueue(payload plunk.TransactionalEmailPayload) (string, error) {
+	return o.EnqueueContext(context.Background(), payload)
+}
+
+// Like Enqueue, but the store is called with ctx.
+func (o *Outbox) EnqueueContext(ctx context.Context, payload plunk.TransactionalEmailPayload) (string, error) {
+	if err := checkPayload(payload); err != nil {
+		return "", err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+
+	if payload.IdempotencyKey == "" {
+		payload.IdempotencyKey = "outbox-" + id
+	}
+
+	now := time.Now()
+	err = o.store.Put(ctx, Message{
+		ID:          id,
+		Payload:     payload,
+		Status:      StatusPending,
+		EnqueuedAt:  now,
+		NextAttempt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+
+	return id, nil
+}
+
+// Catches the mistakes that would make a payload fail on every attempt. The
+// rest are only caught when it is sent.
+func checkPayload(payload plunk.TransactionalEmailPayload) error {
+	if len(payload.To) == 0 {
+		return plunk.ErrMissingTo
+	}
+
+	// the subject and body of templates are only known once it is fetched
+	if payload.Template != "" {
+		return nil
+	}
+
+	if payload.Subject == "" {
+		return plunk.ErrMissingSubject
+	}
+
+	if payload.Body == "" {
+		return plunk.ErrMissingBody
+	}
+
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Returns the message with the given ID, or ErrNotFound.
+func (o *Outbox) Status(id string) (Message, error) {
+	return o.StatusContext(context.Background(), id)
+}
+
+// Like Status, but the store is called with ctx.
+func (o *Outbox) StatusContext(ctx context.Context, id string) (Message, error) {
+	return o.store.Get(ctx, id)
+}
+
+// Returns the messages with the given status, in the order they were
+// enqueued, e.g. StatusDead to list dead letters. An empty status lists every
+// message.
+func (o *Outbox) List(status Status) ([]Message, error) {
+	return o.ListContext(context.Background(), status)
+}
+
+// Like List, but the store is called with ctx.
+func (o *Outbox) ListContext(ctx context.Context, status Status) ([]Message, error) {
+	return o.store.List(ctx, status)
+}
+
+// Puts a dead message back in the queue, with a fresh set of attempts.
+func (o *Outbox) Requeue(id string) error {
+	return o.RequeueContext(context.Background(), id)
+}
+
+// Like Requeue, but the store is called with ctx.
+func (o *Outbox) RequeueContext(ctx context.Context, id string) error {
+	m, err := o.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if m.Status != StatusDead {
+		return fmt.Errorf("%w: %q is %s", ErrNotDead, id, m.Status)
+	}
+
+	m.Status = StatusPending
+	m.Attempts = 0
+	m.NextAttempt = time.Now()
+
+	if err := o.store.Put(ctx, m); err != nil {
+		return err
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Sends messages as they become due, until ctx is done or the store fails.
+// It waits for the emails being sent to finish before returning. Emails cut
+// short by ctx are not counted as attempts, and are sent again by the next
+// Run.
+//
+// When the outcome of an email cannot be recorded, the error is logged with
+// the client's Logger and Run returns it, rather than send the email again
+// while the store is failing. The email is still pending, and is sent again
+// by the next Run. Only call Run once at a time.
+func (o *Outbox) Run(ctx context.Context) error {
+	o.mu.Lock()
+	o.storeErr = nil
+	o.mu.Unlock()
+
+	jobs := make(chan Message)
+
+	var wg sync.WaitGroup
+	for w := 0; w < o.opts.Concurrency; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for m := range jobs {
+				o.deliver(ctx, m)
+			}
+		}()
+	}
+
+	defer wg.Wait()
+	defer close(jobs)
+
+	ticker := time.NewTicker(o.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := o.dispatch(ctx, jobs); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-o.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Hands every pending message that is due, and not being sent already, to
+// the workers.
+func (o *Outbox) dispatch(ctx context.Context, jobs chan<- Message) error {
+	o.mu.Lock()
+	storeErr := o.storeErr
+	o.mu.Unlock()
+
+	if storeErr != nil {
+		return storeErr
+	}
+
+	pending, err := o.store.List(ctx, StatusPending)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, m := range pending {
+		if m.NextAttempt.After(now) {
+			continue
+		}
+
+		// checked under the same lock, so that a message whose outcome could
+		// not be recorded is not handed out again by this Run
+		o.mu.Lock()
+		storeErr, busy := o.storeErr, o.inFlight[m.ID]
+		if storeErr == nil {
+			o.inFlight[m.ID] = true
+		}
+		o.mu.Unlock()
+
+		if storeErr != nil {
+			return storeErr
+		}
+
+		if busy {
+			continue
+		}
+
+		// the list may predate a worker recording the message as sent
+		id := m.ID
+		if m, err = o.store.Get(ctx, id); err != nil || m.Status != StatusPending {
+			o.done(id)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		select {
+		case jobs <- m:
+		case <-ctx.Done():
+			o.done(m.ID)
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (o *Outbox) done(id string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.inFlight, id)
+}
+
+// Sends m and records the outcome.
+func (o *Outbox) deliver(ctx context.Context, m Message) {
+	defer o.done(m.ID)
+
+	res, err := o.p.SendTransactionalEmailContext(ctx, m.Payload)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	now := time.Now()
+	m.Attempts++
+
+	switch {
+	case err == nil:
+		m.Status = StatusSent
+		m.SentAt = now
+		m.LastError = ""
+		m.Response = res
+	case permanent(err) || m.Attempts >= o.opts.MaxAttempts:
+		m.Status = StatusDead
+		m.LastError = err.Error()
+	default:
+		m.LastError = err.Error()
+		m.NextAttempt = now.Add(o.opts.Backoff(m.Attempts + 1))
+	}
+
+	// the outcome is recorded even when ctx is done by now
+	if err := o.store.Put(context.Background(), m); err != nil {
+		o.fail(m, err)
+	}
+}
+
+// Stops Run after the outcome of m could not be recorded. This happens before
+// m is done, so that it is not dispatched again in the meantime.
+func (o *Outbox) fail(m Message, err error) {
+	o.p.Log().Error("could not record outcome", "message_id", m.ID, "status", m.Status, "error", err)
+
+	o.mu.Lock()
+	if o.storeErr == nil {
+		o.storeErr = fmt.Errorf("recording message %q: %w", m.ID, err)
+	}
+	o.mu.Unlock()
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Reports whether err would happen again however many times the email is
+// sent: the payload is invalid, or Plunk rejected it.
+func permanent(err error) bool {
+	var apiErr *plunk.CustomError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= 400 && apiErr.Code < 500 &&
+			apiErr.Code != http.StatusRequestTimeout && apiErr.Code != http.StatusTooManyRequests
+	}
+
+	var validationErr *plunk.ValidationError
+	if errors.As(err, &validationErr) {
+		return true
+	}
+
+	for _, target := range []error{
+		plunk.ErrMissingTo,
+		plunk.ErrMissingSubject,
+		plunk.ErrMissingBody,
+		plunk.ErrMissingFilename,
+		plunk.ErrEmptyAttachment,
+		plunk.ErrAttachmentTooLarge,
+		plunk.ErrUnknownBodyFormat,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
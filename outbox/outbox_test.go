@@ -0,0 +1,249 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kayode0x/plunk"
+	"github.com/kayode0x/plunk/plunktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T) (*plunk.Plunk, *plunktest.Server) {
+	t.Helper()
+
+	srv := plunktest.NewServer()
+	t.Cleanup(srv.Close)
+
+	p, err := plunk.New(srv.ApiKey, &plunk.Config{BaseUrl: srv.BaseUrl})
+	assert.Nil(t, err)
+
+	return p, srv
+}
+
+// Runs o until the test ends.
+func run(t *testing.T, o *Outbox) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- o.Run(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		assert.True(t, errors.Is(<-done, context.Canceled))
+	})
+}
+
+// Waits for the message with the given ID to reach status.
+func waitFor(t *testing.T, o *Outbox, id string, status Status) Message {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m, err := o.Status(id)
+		assert.Nil(t, err)
+
+		if m.Status == status || time.Now().After(deadline) {
+			assert.Equal(t, status, m.Status)
+			return m
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+var testPayload = plunk.TransactionalEmailPayload{
+	To:      []string{"test@example.com"},
+	Subject: "Your order",
+	Body:    "Thanks for your order.",
+}
+
+var noBackoff = func(attempt int) time.Duration { return 0 }
+
+func TestOutbox(t *testing.T) {
+	p, srv := newTestClient(t)
+	o := New(p, NewMemoryStore(), Options{PollInterval: 10 * time.Millisecond})
+
+	id, err := o.Enqueue(testPayload)
+	assert.Nil(t, err)
+
+	m, err := o.Status(id)
+	assert.Nil(t, err)
+	assert.Equal(t, StatusPending, m.Status)
+	assert.Equal(t, "outbox-"+id, m.Payload.IdempotencyKey)
+
+	run(t, o)
+
+	m = waitFor(t, o, id, StatusSent)
+	assert.Equal(t, 1, m.Attempts)
+	assert.False(t, m.SentAt.IsZero())
+	assert.True(t, m.Response.Success)
+
+	emails := srv.Emails()
+	assert.Len(t, emails, 1)
+	assert.Equal(t, "Your order", emails[0].Subject)
+
+	// messages enqueued while running are sent right away
+	id, err = o.Enqueue(testPayload)
+	assert.Nil(t, err)
+	waitFor(t, o, id, StatusSent)
+	assert.Len(t, srv.Emails(), 2)
+
+	_, err = o.Status("missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestOutboxRetries(t *testing.T) {
+	p, srv := newTestClient(t)
+	o := New(p, NewMemoryStore(), Options{PollInterval: 10 * time.Millisecond, Backoff: noBackoff})
+
+	srv.Fail("POST /send", plunktest.Failure{Status: 503, Message: "Service unavailable", Times: 2})
+
+	id, err := o.Enqueue(testPayload)
+	assert.Nil(t, err)
+
+	run(t, o)
+
+	m := waitFor(t, o, id, StatusSent)
+	assert.Equal(t, 3, m.Attempts)
+	assert.Equal(t, "", m.LastError)
+	assert.Len(t, srv.Emails(), 1)
+}
+
+func TestOutboxDeadLetters(t *testing.T) {
+	p, srv := newTestClient(t)
+	o := New(p, NewMemoryStore(), Options{PollInterval: 10 * time.Millisecond, Backoff: noBackoff, MaxAttempts: 3})
+
+	// rejected emails are dead-lettered right away
+	srv.Fail("POST /send", plunktest.Failure{Status: 400, Message: "Invalid email", Times: 1})
+	rejected, err := o.Enqueue(testPayload)
+	assert.Nil(t, err)
+
+	run(t, o)
+
+	m := waitFor(t, o, rejected, StatusDead)
+	assert.Equal(t, 1, m.Attempts)
+	assert.Contains(t, m.LastError, "Invalid email")
+
+	// others once they run out of attempts
+	srv.Fail("POST /send", plunktest.Failure{Status: 500, Message: "Something went wrong"})
+	failing, err := o.Enqueue(testPayload)
+	assert.Nil(t, err)
+
+	m = waitFor(t, o, failing, StatusDead)
+	assert.Equal(t, 3, m.Attempts)
+
+	dead, err := o.List(StatusDead)
+	assert.Nil(t, err)
+	assert.Len(t, dead, 2)
+	assert.Equal(t, rejected, dead[0].ID)
+
+	srv.ClearFailures()
+	assert.Nil(t, o.Requeue(failing))
+
+	m = waitFor(t, o, failing, StatusSent)
+	assert.Equal(t, 1, m.Attempts)
+
+	err = o.Requeue(failing)
+	assert.True(t, errors.Is(err, ErrNotDead))
+	assert.True(t, errors.Is(o.Requeue("missing"), ErrNotFound))
+}
+
+func TestOutboxEnqueueErrors(t *testing.T) {
+	p, _ := newTestClient(t)
+	o := New(p, NewMemoryStore(), Options{})
+
+	_, err := o.Enqueue(plunk.TransactionalEmailPayload{Subject: "Subject", Body: "Body"})
+	assert.Equal(t, plunk.ErrMissingTo, err)
+
+	_, err = o.Enqueue(plunk.TransactionalEmailPayload{To: []string{"test@example.com"}, Body: "Body"})
+	assert.Equal(t, plunk.ErrMissingSubject, err)
+
+	_, err = o.Enqueue(plunk.TransactionalEmailPayload{To: []string{"test@example.com"}, Subject: "Subject"})
+	assert.Equal(t, plunk.ErrMissingBody, err)
+
+	// templates fill in the subject and body when the email is sent
+	_, err = o.Enqueue(plunk.TransactionalEmailPayload{To: []string{"test@example.com"}, Template: "welcome"})
+	assert.Nil(t, err)
+
+	messages, err := o.List("")
+	assert.Nil(t, err)
+	assert.Len(t, messages, 1)
+}
+
+func TestOutboxSurvivesRestarts(t *testing.T) {
+	p, srv := newTestClient(t)
+	path := filepath.Join(t.TempDir(), "outbox.log")
+
+	store, err := OpenLogStore(path)
+	assert.Nil(t, err)
+
+	payload := testPayload
+	payload.Body = "Thanks for your **order**."
+	payload.BodyFormat = plunk.BodyFormatMarkdown
+	payload.IdempotencyKey = "order-42"
+
+	// enqueued, but the process exits before sending
+	id, err := New(p, store, Options{}).Enqueue(payload)
+	assert.Nil(t, err)
+	assert.Nil(t, store.Close())
+
+	store, err = OpenLogStore(path)
+	assert.Nil(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	o := New(p, store, Options{PollInterval: 10 * time.Millisecond})
+	run(t, o)
+
+	m := waitFor(t, o, id, StatusSent)
+	assert.Equal(t, "order-42", m.Payload.IdempotencyKey)
+	assert.Equal(t, plunk.BodyFormatMarkdown, m.Payload.BodyFormat)
+
+	emails := srv.Emails()
+	assert.Len(t, emails, 1)
+	assert.True(t, strings.Contains(emails[0].Body, "<strong>order</strong>"))
+}
+
+// Fails every Put that records an email as sent.
+type failingStore struct {
+	*MemoryStore
+}
+
+func (s failingStore) Put(ctx context.Context, m Message) error {
+	if m.Status == StatusSent {
+		return errors.New("disk full")
+	}
+
+	return s.MemoryStore.Put(ctx, m)
+}
+
+func TestOutboxStoreFailure(t *testing.T) {
+	p, srv := newTestClient(t)
+
+	var logs strings.Builder
+	p.Logger = plunk.NewPrintLogger(&logs)
+
+	o := New(p, failingStore{NewMemoryStore()}, Options{PollInterval: 10 * time.Millisecond})
+
+	id, err := o.Enqueue(testPayload)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Run stops instead of sending the email again and again
+	err = o.Run(ctx)
+	assert.Contains(t, err.Error(), "disk full")
+	assert.Contains(t, err.Error(), id)
+	assert.Len(t, srv.Emails(), 1)
+	assert.Contains(t, logs.String(), "could not record outcome")
+
+	m, err := o.Status(id)
+	assert.Nil(t, err)
+	assert.Equal(t, StatusPending, m.Status)
+}
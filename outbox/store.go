@@ -0,0 +1,316 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/kayode0x/plunk/internal/fsutil"
+)
+
+// Store persists the messages of an outbox. Put is called every time a
+// message changes, and must not return before the change is durable.
+//
+// Implementations must be safe for concurrent use. A store is drained by a
+// single Outbox at a time.
+type Store interface {
+	// Adds m, or replaces the message with the same ID.
+	Put(ctx context.Context, m Message) error
+	// Returns the message with the given ID, or ErrNotFound.
+	Get(ctx context.Context, id string) (Message, error)
+	// Returns the messages with the given status, or every message when
+	// status is empty, in the order they were enqueued.
+	List(ctx context.Context, status Status) ([]Message, error)
+}
+
+// The latest state of every message, in enqueue order. Not safe for
+// concurrent use on its own.
+type index struct {
+	messages map[string]Message
+	order    []string
+}
+
+func newIndex() *index {
+	return &index{messages: map[string]Message{}}
+}
+
+func (ix *index) put(m Message) {
+	if _, ok := ix.messages[m.ID]; !ok {
+		ix.order = append(ix.order, m.ID)
+	}
+
+	ix.messages[m.ID] = m
+}
+
+func (ix *index) get(id string) (Message, error) {
+	m, ok := ix.messages[id]
+	if !ok {
+		return Message{}, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+
+	return m, nil
+}
+
+func (ix *index) list(status Status) []Message {
+	result := []Message{}
+	for _, id := range ix.order {
+		if m := ix.messages[id]; status == "" || m.Status == status {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+// MemoryStore is a Store that keeps messages in memory, e.g. for tests. Its
+// messages are lost when the process exits.
+type MemoryStore struct {
+	mu sync.Mutex
+	ix *index
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{ix: newIndex()}
+}
+
+func (s *MemoryStore) Put(ctx context.Context, m Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ix.put(m)
+
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ix.get(id)
+}
+
+func (s *MemoryStore) List(ctx context.Context, status Status) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ix.list(status), nil
+}
+
+// LogStore is a Store backed by an append-only file. Every change to a
+// message is appended as a line of JSON and synced to disk before Put
+// returns; when the file is opened, the last line of every message wins.
+// Its messages are also kept in memory.
+//
+// The payload of a message, which may hold up to plunk.MaxAttachmentSize of
+// attachments, is only written when it changes, so retries append little
+// more than the status. The file still grows with every change. Call Compact
+// now and then to rewrite it with only the latest state of each message.
+type LogStore struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	size int64 // bytes of complete lines in f
+	ix   *index
+}
+
+// Opens the log at path, creating it if it does not exist. A last line cut
+// short by a crash is dropped.
+func OpenLogStore(path string) (*LogStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	ix, size, err := readLog(f)
+	if err == nil {
+		// appends start after the last complete line
+		err = f.Truncate(size)
+	}
+	if err == nil {
+		_, err = f.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &LogStore{path: path, f: f, size: size, ix: ix}, nil
+}
+
+// Replays the log, returning the index and the size of its complete lines.
+func readLog(r io.Reader) (*index, int64, error) {
+	ix := newIndex()
+	br := bufio.NewReader(r)
+
+	var size int64
+	for line := 1; ; line++ {
+		b, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			// a line without a newline was being written when the process died
+			return ix, size, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		size += int64(len(b))
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+
+		var m Message
+		hasPayload, err := m.unmarshal(b)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: line %d: %s", ErrCorruptLog, line, err.Error())
+		}
+
+		if !hasPayload {
+			prev, ok := ix.messages[m.ID]
+			if !ok {
+				return nil, 0, fmt.Errorf("%w: line %d: no payload for %q", ErrCorruptLog, line, m.ID)
+			}
+
+			m.Payload = prev.Payload
+		}
+
+		ix.put(m)
+	}
+}
+
+func (s *LogStore) Put(ctx context.Context, m Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return os.ErrClosed
+	}
+
+	prev, ok := s.ix.messages[m.ID]
+	b, err := m.marshal(!ok || !reflect.DeepEqual(prev.Payload, m.Payload))
+	if err != nil {
+		return err
+	}
+
+	n, err := s.f.Write(append(b, '\n'))
+	if err == nil {
+		err = s.f.Sync()
+	}
+
+	if err != nil {
+		// a partial line would corrupt the next one appended after it
+		if n > 0 && s.f.Truncate(s.size) == nil {
+			s.f.Seek(s.size, io.SeekStart)
+		}
+
+		return err
+	}
+
+	s.size += int64(n)
+	s.ix.put(m)
+
+	return nil
+}
+
+func (s *LogStore) Get(ctx context.Context, id string) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ix.get(id)
+}
+
+func (s *LogStore) List(ctx context.Context, status Status) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ix.list(status), nil
+}
+
+// Rewrites the log with the latest state of every message, leaving out
+// messages sent before the given time. Those can no longer be looked up.
+func (s *LogStore) Compact(sentBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return os.ErrClosed
+	}
+
+	ix := newIndex()
+	for _, m := range s.ix.list("") {
+		if m.Status == StatusSent && m.SentAt.Before(sentBefore) {
+			continue
+		}
+
+		ix.put(m)
+	}
+
+	// the new log replaces the old one through a rename, so that a crash
+	// leaves one or the other behind once the directory is synced
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	size, err := writeLog(tmp, ix)
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	// the rename is only durable once the directory is synced; the new log is
+	// in place either way, so it is used even if that fails
+	err = fsutil.SyncDir(filepath.Dir(s.path))
+
+	s.f.Close()
+	s.f, s.size, s.ix = tmp, size, ix
+
+	return err
+}
+
+// Writes the messages of ix to f and syncs it, returning the size written.
+func writeLog(f *os.File, ix *index) (int64, error) {
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, m := range ix.list("") {
+		if err := enc.Encode(m); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+
+	return f.Seek(0, io.SeekCurrent)
+}
+
+// Closes the log. The store cannot be used afterwards.
+func (s *LogStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+
+	err := s.f.Close()
+	s.f = nil
+
+	return err
+}
@@ -0,0 +1,200 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kayode0x/plunk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageJSON(t *testing.T) {
+	m := Message{
+		ID:     "1",
+		Status: StatusPending,
+		Payload: plunk.TransactionalEmailPayload{
+			To:             []string{"a@example.com", "b@example.com"},
+			Template:       "welcome",
+			BodyFormat:     plunk.BodyFormatHTML,
+			IdempotencyKey: "key",
+			Attachments:    []plunk.Attachment{{Filename: "a.txt", ContentType: "text/plain", Content: []byte("hello")}},
+		},
+	}
+
+	b, err := m.MarshalJSON()
+	assert.Nil(t, err)
+
+	var decoded Message
+	assert.Nil(t, decoded.UnmarshalJSON(b))
+	assert.Equal(t, m, decoded)
+}
+
+func TestLogStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "outbox.log")
+
+	s, err := OpenLogStore(path)
+	assert.Nil(t, err)
+
+	first := Message{ID: "1", Payload: testPayload, Status: StatusPending}
+	second := Message{ID: "2", Payload: testPayload, Status: StatusPending}
+	assert.Nil(t, s.Put(ctx, first))
+	assert.Nil(t, s.Put(ctx, second))
+
+	first.Status, first.Attempts = StatusDead, 3
+	assert.Nil(t, s.Put(ctx, first))
+	assert.Nil(t, s.Close())
+	assert.Equal(t, os.ErrClosed, s.Put(ctx, first))
+
+	// the last line of every message wins, and messages keep their order
+	s, err = OpenLogStore(path)
+	assert.Nil(t, err)
+	defer s.Close()
+
+	m, err := s.Get(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, StatusDead, m.Status)
+	assert.Equal(t, 3, m.Attempts)
+
+	all, err := s.List(ctx, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", all[0].ID)
+	assert.Equal(t, "2", all[1].ID)
+
+	pending, err := s.List(ctx, StatusPending)
+	assert.Nil(t, err)
+	assert.Len(t, pending, 1)
+
+	_, err = s.Get(ctx, "3")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestLogStorePayloadWrittenOnce(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "outbox.log")
+
+	s, err := OpenLogStore(path)
+	assert.Nil(t, err)
+
+	payload := testPayload
+	payload.Attachments = []plunk.Attachment{{Filename: "report.pdf", Content: make([]byte, 1<<20)}}
+
+	m := Message{ID: "1", Payload: payload, Status: StatusPending}
+	assert.Nil(t, s.Put(ctx, m))
+	first, _ := os.Stat(path)
+
+	// retries only append the status
+	for m.Attempts = 1; m.Attempts <= 5; m.Attempts++ {
+		m.LastError = "Service unavailable"
+		assert.Nil(t, s.Put(ctx, m))
+	}
+	m.Status = StatusSent
+	assert.Nil(t, s.Put(ctx, m))
+
+	after, _ := os.Stat(path)
+	assert.Less(t, after.Size()-first.Size(), int64(10<<10))
+
+	// a changed payload is written again
+	payload.Subject = "Your receipt"
+	m.Payload = payload
+	assert.Nil(t, s.Put(ctx, m))
+	changed, _ := os.Stat(path)
+	assert.Greater(t, changed.Size()-after.Size(), int64(1<<20))
+	assert.Nil(t, s.Close())
+
+	s, err = OpenLogStore(path)
+	assert.Nil(t, err)
+	defer s.Close()
+
+	stored, err := s.Get(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, m, stored)
+
+	// a status line without an earlier payload is corrupt
+	assert.Nil(t, os.WriteFile(path, []byte("{\"id\":\"1\",\"status\":\"sent\"}\n"), 0o600))
+	_, err = OpenLogStore(path)
+	assert.True(t, errors.Is(err, ErrCorruptLog))
+}
+
+func TestLogStoreTruncatedLine(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "outbox.log")
+
+	s, err := OpenLogStore(path)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Put(ctx, Message{ID: "1", Payload: testPayload, Status: StatusPending}))
+	assert.Nil(t, s.Close())
+
+	// the process died while appending a line
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	assert.Nil(t, err)
+	_, err = f.WriteString(`{"id":"2","payload":{"to":`)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	s, err = OpenLogStore(path)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Put(ctx, Message{ID: "3", Payload: testPayload, Status: StatusPending}))
+	assert.Nil(t, s.Close())
+
+	s, err = OpenLogStore(path)
+	assert.Nil(t, err)
+	defer s.Close()
+
+	all, err := s.List(ctx, "")
+	assert.Nil(t, err)
+	assert.Len(t, all, 2)
+	assert.Equal(t, "3", all[1].ID)
+
+	assert.Nil(t, os.WriteFile(path, []byte("{\"id\":\"1\"}\nnot json\n"), 0o600))
+	_, err = OpenLogStore(path)
+	assert.True(t, errors.Is(err, ErrCorruptLog))
+}
+
+func TestLogStoreCompact(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "outbox.log")
+
+	s, err := OpenLogStore(path)
+	assert.Nil(t, err)
+
+	now := time.Now()
+	for _, m := range []Message{
+		{ID: "old", Payload: testPayload, Status: StatusPending},
+		{ID: "old", Payload: testPayload, Status: StatusSent, SentAt: now.Add(-2 * time.Hour)},
+		{ID: "recent", Payload: testPayload, Status: StatusPending},
+		{ID: "recent", Payload: testPayload, Status: StatusSent, SentAt: now},
+		{ID: "dead", Payload: testPayload, Status: StatusPending},
+		{ID: "dead", Payload: testPayload, Status: StatusDead},
+	} {
+		assert.Nil(t, s.Put(ctx, m))
+	}
+
+	before, _ := os.Stat(path)
+	assert.Nil(t, s.Compact(now.Add(-time.Hour)))
+	after, _ := os.Stat(path)
+	assert.Less(t, after.Size(), before.Size())
+
+	_, err = s.Get(ctx, "old")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	// the compacted log is appended to as before
+	assert.Nil(t, s.Put(ctx, Message{ID: "new", Payload: testPayload, Status: StatusPending}))
+	assert.Nil(t, s.Close())
+
+	s, err = OpenLogStore(path)
+	assert.Nil(t, err)
+	defer s.Close()
+
+	all, err := s.List(ctx, "")
+	assert.Nil(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, []string{"recent", "dead", "new"}, []string{all[0].ID, all[1].ID, all[2].ID})
+
+	files, _ := os.ReadDir(filepath.Dir(path))
+	assert.Len(t, files, 1)
+}
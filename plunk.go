@@ -2,9 +2,9 @@ package plunk
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
 	"os"
+	"time"
 )
 
 var (
@@ -15,17 +15,58 @@ const (
 	transactionalEmailEndpoint  = "/send"
 	eventsEndpoint              = "/track"
 	deleteEventEndpoint         = "/events"
+	listEventsEndpoint          = "/events"
 	contactsEndpoint            = "/contacts"
 	contactsCountEndpoint       = "/contacts/count"
 	contactsSubscribeEndpoint   = "/contacts/subscribe"
 	contactsUnsubscribeEndpoint = "/contacts/unsubscribe"
+	campaignsEndpoint           = "/campaigns"
+	campaignsSendEndpoint       = "/campaigns/send"
+	templatesEndpoint           = "/templates"
+	actionsEndpoint             = "/actions"
 )
 
 type Config struct {
 	ApiKey  string
 	Client  *http.Client
 	BaseUrl string
-	Debug   bool
+	Debug   bool         // Print logs to stdout when no Logger is set.
+	Retry   *RetryPolicy // When nil, failed requests are not retried.
+
+	// Receives the client's logs. A *slog.Logger can be used as is.
+	Logger Logger
+
+	// Log request bodies verbatim. By default email bodies, attachments,
+	// headers and contact or event data are redacted.
+	LogSensitive bool
+
+	// Limits how many requests per second the client sends, with bursts of up
+	// to RateBurst requests. Ignored when RateLimiter is set.
+	RateLimit float64
+	RateBurst int
+
+	// Shared by every request made through the client. When nil and RateLimit
+	// is set, New creates a TokenBucket.
+	RateLimiter RateLimiter
+
+	// Maximum number of requests a bulk operation such as
+	// SendMultipleTransactionalEmails runs at once. Defaults to 10.
+	Concurrency int
+
+	// Checks and normalizes email addresses before they are sent. When nil,
+	// addresses are sent as they are.
+	Validator *EmailValidator
+
+	// Templates rendered by the client, see RegisterTemplate. When nil, New
+	// creates an empty registry.
+	LocalTemplates *LocalTemplates
+
+	// Remembers the responses of emails and events sent with an
+	// IdempotencyKey, for IdempotencyTTL (24 hours by default). When nil,
+	// keys are only sent to Plunk as the Idempotency-Key header, which it is
+	// not known to honor.
+	Idempotency    IdempotencyStore
+	IdempotencyTTL time.Duration
 }
 
 func (p *Plunk) defaultConfig() *Config {
@@ -34,6 +75,21 @@ func (p *Plunk) defaultConfig() *Config {
 		Client:  http.DefaultClient,
 		BaseUrl: "https://api.useplunk.com/v1",
 		Debug:   false,
+		Retry:   nil,
+
+		RateLimit:   0,
+		RateBurst:   0,
+		RateLimiter: nil,
+		Concurrency: defaultConcurrency,
+
+		Logger:       nil,
+		LogSensitive: false,
+
+		Validator:      nil,
+		LocalTemplates: NewLocalTemplates(),
+
+		Idempotency:    nil,
+		IdempotencyTTL: defaultIdempotencyTTL,
 	}
 }
 
@@ -62,6 +118,48 @@ func New(apiKey string, c *Config) (*Plunk, error) {
 		if c.Debug {
 			config.Debug = c.Debug
 		}
+
+		if c.Retry != nil {
+			config.Retry = c.Retry
+		}
+
+		if c.RateLimit > 0 {
+			config.RateLimit = c.RateLimit
+			config.RateBurst = c.RateBurst
+			config.RateLimiter = NewTokenBucket(c.RateLimit, c.RateBurst)
+		}
+
+		if c.RateLimiter != nil {
+			config.RateLimiter = c.RateLimiter
+		}
+
+		if c.Concurrency > 0 {
+			config.Concurrency = c.Concurrency
+		}
+
+		if c.Logger != nil {
+			config.Logger = c.Logger
+		}
+
+		if c.LogSensitive {
+			config.LogSensitive = c.LogSensitive
+		}
+
+		if c.Validator != nil {
+			config.Validator = c.Validator
+		}
+
+		if c.LocalTemplates != nil {
+			config.LocalTemplates = c.LocalTemplates
+		}
+
+		if c.Idempotency != nil {
+			config.Idempotency = c.Idempotency
+		}
+
+		if c.IdempotencyTTL > 0 {
+			config.IdempotencyTTL = c.IdempotencyTTL
+		}
 	}
 
 	config.ApiKey = apiKey
@@ -84,18 +182,3 @@ func NewFromEnv() (*Plunk, error) {
 func (p *Plunk) url(endpoint string) string {
 	return p.BaseUrl + endpoint
 }
-
-// logger
-func (p *Plunk) log(level string, a interface{}) {
-	if p.Debug {
-		fmt.Printf("[%s] %s\n", level, a)
-	}
-}
-
-func (p *Plunk) logError(a any) {
-	p.log("ERROR", a)
-}
-
-func (p *Plunk) logInfo(a any) {
-	p.log("INFO", a)
-}
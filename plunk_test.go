@@ -0,0 +1,50 @@
+package plunk
+
+import (
+	"testing"
+
+	"github.com/kayode0x/plunk/plunktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a client talking to a fresh fake Plunk API.
+func newTestClient(t *testing.T) (*Plunk, *plunktest.Server) {
+	t.Helper()
+
+	server := plunktest.NewServer()
+	t.Cleanup(server.Close)
+
+	p, err := New(server.ApiKey, &Config{BaseUrl: server.BaseUrl})
+	assert.Nil(t, err)
+
+	return p, server
+}
+
+func TestNew(t *testing.T) {
+	p, err := New("", nil)
+	assert.Equal(t, ErrNoAPIKey, err)
+	assert.Nil(t, p)
+
+	p, err = New("test-api-key", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "test-api-key", p.ApiKey)
+	assert.Equal(t, "https://api.useplunk.com/v1", p.BaseUrl)
+	assert.False(t, p.Debug)
+
+	p, err = New("test-api-key", &Config{BaseUrl: "http://localhost", Debug: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost", p.BaseUrl)
+	assert.True(t, p.Debug)
+	assert.Equal(t, "http://localhost/contacts", p.url(contactsEndpoint))
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("PLUNK_API_KEY", "")
+	_, err := NewFromEnv()
+	assert.Equal(t, ErrNoAPIKey, err)
+
+	t.Setenv("PLUNK_API_KEY", "env-api-key")
+	p, err := NewFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, "env-api-key", p.ApiKey)
+}
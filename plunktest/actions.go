@@ -0,0 +1,226 @@
+package plunktest
+
+import "net/http"
+
+// Action is a snapshot of an action stored by the fake.
+type Action struct {
+	ID         string
+	Name       string
+	RunOnce    bool
+	Delay      int
+	TemplateID string
+	Events     []string // IDs of the events that trigger the action
+	NotEvents  []string // IDs of the events that stop the action from running
+}
+
+type action struct {
+	ID         string
+	Name       string
+	RunOnce    bool
+	Delay      int
+	TemplateID string
+	Events     []string
+	NotEvents  []string
+	CreatedAt  string
+	UpdatedAt  string
+}
+
+type actionPayload struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	RunOnce   bool     `json:"runOnce"`
+	Delay     int      `json:"delay"`
+	Template  string   `json:"template"`
+	Events    []string `json:"events"`
+	NotEvents []string `json:"notevents"`
+}
+
+// Actions returns every stored action, in the order they were created.
+func (s *Server) Actions() []Action {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	actions := make([]Action, len(s.actions))
+	for i, a := range s.actions {
+		actions[i] = Action{
+			ID:         a.ID,
+			Name:       a.Name,
+			RunOnce:    a.RunOnce,
+			Delay:      a.Delay,
+			TemplateID: a.TemplateID,
+			Events:     append([]string{}, a.Events...),
+			NotEvents:  append([]string{}, a.NotEvents...),
+		}
+	}
+
+	return actions
+}
+
+// Callers must hold s.mu.
+func (s *Server) actionByID(id string) *action {
+	for _, a := range s.actions {
+		if a.ID == id {
+			return a
+		}
+	}
+
+	return nil
+}
+
+// Renders an action the way the API does, with its events expanded but
+// without their triggers.
+// Callers must hold s.mu.
+func (s *Server) actionJSON(a *action) map[string]interface{} {
+	expand := func(ids []string) []event {
+		events := []event{}
+		for _, id := range ids {
+			if e := s.eventByID(id); e != nil {
+				expanded := *e
+				expanded.Triggers = nil
+				events = append(events, expanded)
+			}
+		}
+
+		return events
+	}
+
+	return map[string]interface{}{
+		"id":         a.ID,
+		"name":       a.Name,
+		"runOnce":    a.RunOnce,
+		"delay":      a.Delay,
+		"templateId": a.TemplateID,
+		"events":     expand(a.Events),
+		"notevents":  expand(a.NotEvents),
+		"projectId":  projectID,
+		"createdAt":  a.CreatedAt,
+		"updatedAt":  a.UpdatedAt,
+	}
+}
+
+// Checks that the action references a template and events that exist.
+// Callers must hold s.mu.
+func (s *Server) validateAction(w http.ResponseWriter, payload actionPayload) bool {
+	if payload.Name == "" || payload.Template == "" || len(payload.Events) == 0 || payload.Delay < 0 {
+		writeError(w, http.StatusBadRequest, "Missing name, template or events")
+		return false
+	}
+
+	if s.templateByID(payload.Template) == nil {
+		writeError(w, http.StatusNotFound, "That template was not found")
+		return false
+	}
+
+	for _, id := range append(append([]string{}, payload.Events...), payload.NotEvents...) {
+		if s.eventByID(id) == nil {
+			writeError(w, http.StatusNotFound, "That event was not found")
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) getActions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	actions := []map[string]interface{}{}
+	for _, a := range s.actions {
+		actions = append(actions, s.actionJSON(a))
+	}
+
+	writeJSON(w, http.StatusOK, actions)
+}
+
+func (s *Server) getAction(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.actionByID(pathID(r))
+	if a == nil {
+		writeError(w, http.StatusNotFound, "That action was not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.actionJSON(a))
+}
+
+func (s *Server) createAction(w http.ResponseWriter, r *http.Request) {
+	var payload actionPayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.validateAction(w, payload) {
+		return
+	}
+
+	a := &action{
+		ID:         newID(),
+		Name:       payload.Name,
+		RunOnce:    payload.RunOnce,
+		Delay:      payload.Delay,
+		TemplateID: payload.Template,
+		Events:     payload.Events,
+		NotEvents:  payload.NotEvents,
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+	}
+	s.actions = append(s.actions, a)
+
+	writeJSON(w, http.StatusOK, s.actionJSON(a))
+}
+
+func (s *Server) updateAction(w http.ResponseWriter, r *http.Request) {
+	var payload actionPayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.actionByID(payload.ID)
+	if a == nil {
+		writeError(w, http.StatusNotFound, "That action was not found")
+		return
+	}
+
+	if !s.validateAction(w, payload) {
+		return
+	}
+
+	a.Name = payload.Name
+	a.RunOnce = payload.RunOnce
+	a.Delay = payload.Delay
+	a.TemplateID = payload.Template
+	a.Events = payload.Events
+	a.NotEvents = payload.NotEvents
+	a.UpdatedAt = now()
+
+	writeJSON(w, http.StatusOK, s.actionJSON(a))
+}
+
+func (s *Server) deleteAction(w http.ResponseWriter, r *http.Request) {
+	var payload actionPayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, a := range s.actions {
+		if a.ID == payload.ID {
+			s.actions = append(s.actions[:i], s.actions[i+1:]...)
+			writeJSON(w, http.StatusOK, s.actionJSON(a))
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "That action was not found")
+}
@@ -0,0 +1,216 @@
+package plunktest
+
+import (
+	"net/http"
+	"time"
+)
+
+// Campaign is a snapshot of a campaign stored by the fake.
+type Campaign struct {
+	ID         string
+	Subject    string
+	Body       string
+	Recipients []string
+	Style      string
+	Status     string
+}
+
+// SentCampaign is a request to send a campaign the fake accepted through /campaigns/send.
+type SentCampaign struct {
+	CampaignID string
+	Recipients []string
+	Live       bool
+	Delay      int // minutes
+	SentAt     time.Time
+}
+
+type campaign struct {
+	ID         string   `json:"id"`
+	Subject    string   `json:"subject"`
+	Body       string   `json:"body"`
+	Status     string   `json:"status"`
+	Delivered  *string  `json:"delivered"`
+	Style      string   `json:"style"`
+	ProjectID  string   `json:"projectId"`
+	CreatedAt  string   `json:"createdAt"`
+	UpdatedAt  string   `json:"updatedAt"`
+	Recipients []string `json:"-"`
+}
+
+type campaignPayload struct {
+	ID         string   `json:"id"`
+	Subject    string   `json:"subject"`
+	Body       string   `json:"body"`
+	Recipients []string `json:"recipients"`
+	Style      string   `json:"style"`
+}
+
+// Campaigns returns every stored campaign, in the order they were created.
+func (s *Server) Campaigns() []Campaign {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	campaigns := make([]Campaign, len(s.campaigns))
+	for i, c := range s.campaigns {
+		campaigns[i] = Campaign{
+			ID:         c.ID,
+			Subject:    c.Subject,
+			Body:       c.Body,
+			Recipients: append([]string{}, c.Recipients...),
+			Style:      c.Style,
+			Status:     c.Status,
+		}
+	}
+
+	return campaigns
+}
+
+// SentCampaigns returns every campaign send accepted through /campaigns/send, oldest first.
+func (s *Server) SentCampaigns() []SentCampaign {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SentCampaign{}, s.sentCampaigns...)
+}
+
+// Callers must hold s.mu.
+func (s *Server) campaignByID(id string) *campaign {
+	for _, c := range s.campaigns {
+		if c.ID == id {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func validateCampaign(w http.ResponseWriter, payload campaignPayload) bool {
+	if payload.Subject == "" || payload.Body == "" || len(payload.Recipients) == 0 {
+		writeError(w, http.StatusBadRequest, "Missing subject, body or recipients")
+		return false
+	}
+
+	if payload.Style != "" && payload.Style != "PLUNK" && payload.Style != "HTML" {
+		writeError(w, http.StatusBadRequest, "Invalid style")
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) createCampaign(w http.ResponseWriter, r *http.Request) {
+	var payload campaignPayload
+	if !decodeBody(w, r, &payload) || !validateCampaign(w, payload) {
+		return
+	}
+
+	if payload.Style == "" {
+		payload.Style = "PLUNK"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &campaign{
+		ID:         newID(),
+		Subject:    payload.Subject,
+		Body:       payload.Body,
+		Status:     "DRAFT",
+		Style:      payload.Style,
+		ProjectID:  projectID,
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+		Recipients: payload.Recipients,
+	}
+	s.campaigns = append(s.campaigns, c)
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) updateCampaign(w http.ResponseWriter, r *http.Request) {
+	var payload campaignPayload
+	if !decodeBody(w, r, &payload) || !validateCampaign(w, payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.campaignByID(payload.ID)
+	if c == nil {
+		writeError(w, http.StatusNotFound, "That campaign was not found")
+		return
+	}
+
+	c.Subject = payload.Subject
+	c.Body = payload.Body
+	c.Recipients = payload.Recipients
+	if payload.Style != "" {
+		c.Style = payload.Style
+	}
+	c.UpdatedAt = now()
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) deleteCampaign(w http.ResponseWriter, r *http.Request) {
+	var payload campaignPayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, c := range s.campaigns {
+		if c.ID == payload.ID {
+			s.campaigns = append(s.campaigns[:i], s.campaigns[i+1:]...)
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "That campaign was not found")
+}
+
+func (s *Server) sendCampaign(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		ID    string `json:"id"`
+		Live  bool   `json:"live"`
+		Delay int    `json:"delay"`
+	}
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.campaignByID(payload.ID)
+	if c == nil {
+		writeError(w, http.StatusNotFound, "That campaign was not found")
+		return
+	}
+
+	if c.Status == "DELIVERED" {
+		writeError(w, http.StatusBadRequest, "This campaign has already been sent")
+		return
+	}
+
+	if payload.Live {
+		delivered := time.Now().Add(time.Duration(payload.Delay) * time.Minute).UTC().Format(time.RFC3339Nano)
+		c.Status = "DELIVERED"
+		c.Delivered = &delivered
+		c.UpdatedAt = now()
+	}
+
+	s.sentCampaigns = append(s.sentCampaigns, SentCampaign{
+		CampaignID: c.ID,
+		Recipients: append([]string{}, c.Recipients...),
+		Live:       payload.Live,
+		Delay:      payload.Delay,
+		SentAt:     time.Now(),
+	})
+
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
@@ -0,0 +1,364 @@
+package plunktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Contact is a snapshot of a contact stored by the fake.
+type Contact struct {
+	ID         string
+	Email      string
+	Subscribed bool
+	Data       map[string]interface{}
+}
+
+type contact struct {
+	ID         string  `json:"id"`
+	Email      string  `json:"email"`
+	Subscribed bool    `json:"subscribed"`
+	Data       *string `json:"data"` // the API stores contact data as a JSON string
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+type contactPayload struct {
+	ID         string          `json:"id"`
+	Email      string          `json:"email"`
+	Subscribed *bool           `json:"subscribed"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Clients send data either as an object or as an already encoded JSON string.
+func normalizeData(raw json.RawMessage) (*string, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, true
+	}
+
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		if str == "" {
+			return nil, true
+		}
+
+		if !json.Valid([]byte(str)) {
+			return nil, false
+		}
+
+		return &str, true
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, false
+	}
+
+	str = string(raw)
+	return &str, true
+}
+
+func (c *contact) snapshot() Contact {
+	var data map[string]interface{}
+	if c.Data != nil {
+		json.Unmarshal([]byte(*c.Data), &data)
+	}
+
+	return Contact{
+		ID:         c.ID,
+		Email:      c.Email,
+		Subscribed: c.Subscribed,
+		Data:       data,
+	}
+}
+
+// Merges the keys of data into c's stored data.
+func (c *contact) mergeData(data map[string]interface{}) {
+	if len(data) == 0 {
+		return
+	}
+
+	merged := map[string]interface{}{}
+	if c.Data != nil {
+		json.Unmarshal([]byte(*c.Data), &merged)
+	}
+
+	for key, value := range data {
+		merged[key] = value
+	}
+
+	b, _ := json.Marshal(merged)
+	str := string(b)
+	c.Data = &str
+}
+
+// Callers must hold s.mu.
+func (s *Server) contactByID(id string) *contact {
+	for _, c := range s.contacts {
+		if c.ID == id {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Callers must hold s.mu.
+func (s *Server) contactByEmail(email string) *contact {
+	for _, c := range s.contacts {
+		if strings.EqualFold(c.Email, email) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Returns the contact with the given email, creating it when it doesn't exist.
+// Callers must hold s.mu.
+func (s *Server) upsertContact(email string, subscribed bool) *contact {
+	if c := s.contactByEmail(email); c != nil {
+		return c
+	}
+
+	c := &contact{
+		ID:         newID(),
+		Email:      email,
+		Subscribed: subscribed,
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+	}
+	s.contacts = append(s.contacts, c)
+
+	return c
+}
+
+// Contacts returns every stored contact, in the order they were created.
+func (s *Server) Contacts() []Contact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contacts := make([]Contact, len(s.contacts))
+	for i, c := range s.contacts {
+		contacts[i] = c.snapshot()
+	}
+
+	return contacts
+}
+
+// Contact returns the stored contact with the given email, if any.
+func (s *Server) Contact(email string) (Contact, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.contactByEmail(email)
+	if c == nil {
+		return Contact{}, false
+	}
+
+	return c.snapshot(), true
+}
+
+// AddContact stores a contact as if it had been created through the API, and
+// returns it with its ID. An existing contact with the same email is replaced.
+func (s *Server) AddContact(email string, subscribed bool, data map[string]interface{}) Contact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.upsertContact(email, subscribed)
+	c.Subscribed = subscribed
+	c.Data = nil
+	c.mergeData(data)
+
+	return c.snapshot()
+}
+
+// Lists contacts in the order they were created. With a limit, at most that
+// many contacts are returned, starting after the contact whose ID is the cursor.
+func (s *Server) getContacts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page := s.contacts
+
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		i := s.contactIndex(cursor)
+		if i < 0 {
+			writeError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+
+		page = page[i+1:]
+	}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+
+		if n < len(page) {
+			page = page[:n]
+		}
+	}
+
+	contacts := make([]contact, len(page))
+	for i, c := range page {
+		contacts[i] = *c
+	}
+
+	writeJSON(w, http.StatusOK, contacts)
+}
+
+// Returns the position of the contact with the given ID, or -1.
+// Callers must hold s.mu.
+func (s *Server) contactIndex(id string) int {
+	for i, c := range s.contacts {
+		if c.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *Server) getContactsCount(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]int{"count": len(s.contacts)})
+}
+
+func (s *Server) getContact(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.contactByID(pathID(r))
+	if c == nil {
+		writeError(w, http.StatusNotFound, "That contact was not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) createContact(w http.ResponseWriter, r *http.Request) {
+	var payload contactPayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	if payload.Email == "" {
+		writeError(w, http.StatusBadRequest, "Missing email")
+		return
+	}
+
+	data, ok := normalizeData(payload.Data)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid data")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// mirrors the response the live API gives for duplicates
+	if s.contactByEmail(payload.Email) != nil {
+		writeError(w, http.StatusInternalServerError, "Contact already exists")
+		return
+	}
+
+	c := s.upsertContact(payload.Email, payload.Subscribed != nil && *payload.Subscribed)
+	c.Data = data
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) updateContact(w http.ResponseWriter, r *http.Request) {
+	var payload contactPayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	data, ok := normalizeData(payload.Data)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid data")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.contactByID(payload.ID)
+	if c == nil {
+		writeError(w, http.StatusNotFound, "That contact was not found")
+		return
+	}
+
+	if payload.Email != "" {
+		c.Email = payload.Email
+	}
+
+	if payload.Subscribed != nil {
+		c.Subscribed = *payload.Subscribed
+	}
+
+	c.Data = data
+	c.UpdatedAt = now()
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) deleteContact(w http.ResponseWriter, r *http.Request) {
+	var payload contactPayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, c := range s.contacts {
+		if c.ID == payload.ID {
+			s.contacts = append(s.contacts[:i], s.contacts[i+1:]...)
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "That contact was not found")
+}
+
+func (s *Server) subscribeContact(w http.ResponseWriter, r *http.Request) {
+	s.setSubscribed(w, r, true)
+}
+
+func (s *Server) unsubscribeContact(w http.ResponseWriter, r *http.Request) {
+	s.setSubscribed(w, r, false)
+}
+
+func (s *Server) setSubscribed(w http.ResponseWriter, r *http.Request, subscribed bool) {
+	var payload contactPayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.contactByID(payload.ID)
+	if c == nil {
+		writeError(w, http.StatusNotFound, "That contact was not found")
+		return
+	}
+
+	// like Plunk, answer with the contact as it was before the change
+	before := *c
+
+	c.Subscribed = subscribed
+	c.UpdatedAt = now()
+
+	writeJSON(w, http.StatusOK, before)
+}
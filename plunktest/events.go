@@ -0,0 +1,171 @@
+package plunktest
+
+import (
+	"net/http"
+	"time"
+)
+
+// TrackedEvent is an event the fake accepted through /track.
+type TrackedEvent struct {
+	Event      string
+	Email      string
+	Data       map[string]interface{}
+	Subscribed bool // whether the contact is subscribed after the event
+	TrackedAt  time.Time
+}
+
+type event struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+	ProjectID  string  `json:"projectId"`
+	CampaignID *string `json:"campaignId"`
+	TemplateID *string `json:"templateId"`
+
+	Triggers []trigger `json:"triggers,omitempty"`
+}
+
+type trigger struct {
+	ID        string `json:"id"`
+	ContactID string `json:"contactId"`
+	EventID   string `json:"eventId"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type trackPayload struct {
+	Event      string                 `json:"event"`
+	Email      string                 `json:"email"`
+	Data       map[string]interface{} `json:"data"`
+	Subscribed *bool                  `json:"subscribed"`
+}
+
+// The project every fake event belongs to.
+const projectID = "plunktest"
+
+// Callers must hold s.mu.
+func (s *Server) eventByName(name string) *event {
+	for _, e := range s.events {
+		if e.Name == name {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// Callers must hold s.mu.
+func (s *Server) eventByID(id string) *event {
+	for _, e := range s.events {
+		if e.ID == id {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) track(w http.ResponseWriter, r *http.Request) {
+	var payload trackPayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	if payload.Event == "" || payload.Email == "" {
+		writeError(w, http.StatusBadRequest, "Missing event or email")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.eventByName(payload.Event)
+	if e == nil {
+		e = &event{
+			ID:        newID(),
+			Name:      payload.Event,
+			CreatedAt: now(),
+			UpdatedAt: now(),
+			ProjectID: projectID,
+		}
+		s.events = append(s.events, e)
+	}
+
+	// contacts are subscribed unless the event says otherwise
+	subscribed := payload.Subscribed == nil || *payload.Subscribed
+	c := s.upsertContact(payload.Email, subscribed)
+	c.mergeData(payload.Data)
+
+	e.Triggers = append(e.Triggers, trigger{
+		ID:        newID(),
+		ContactID: c.ID,
+		EventID:   e.ID,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	})
+
+	s.tracked = append(s.tracked, TrackedEvent{
+		Event:      payload.Event,
+		Email:      payload.Email,
+		Data:       payload.Data,
+		Subscribed: c.Subscribed,
+		TrackedAt:  time.Now(),
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"contact": c.ID,
+		"event":   e.ID,
+	})
+}
+
+func (s *Server) getEvents(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, append([]*event{}, s.events...))
+}
+
+func (s *Server) getEvent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.eventByID(pathID(r))
+	if e == nil {
+		writeError(w, http.StatusNotFound, "That event was not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (s *Server) deleteEvent(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		ID string `json:"id"`
+	}
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, e := range s.events {
+		if e.ID == payload.ID {
+			s.events = append(s.events[:i], s.events[i+1:]...)
+			writeJSON(w, http.StatusOK, e)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "That event was not found")
+}
+
+// TrackedEvents returns every event tracked through /track, oldest first.
+func (s *Server) TrackedEvents() []TrackedEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]TrackedEvent{}, s.tracked...)
+}
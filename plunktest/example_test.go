@@ -0,0 +1,45 @@
+package plunktest_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kayode0x/plunk"
+	"github.com/kayode0x/plunk/plunktest"
+)
+
+func ExampleNewServer() {
+	srv := plunktest.NewServer()
+	defer srv.Close()
+
+	p, _ := plunk.New(srv.ApiKey, &plunk.Config{BaseUrl: srv.BaseUrl})
+
+	p.SendTransactionalEmail(plunk.TransactionalEmailPayload{
+		To:      []string{"user@example.com"},
+		Subject: "Welcome",
+		Body:    "# Hello there",
+	})
+
+	for _, email := range srv.Emails() {
+		fmt.Println(email.To, email.Subject)
+	}
+	// Output: user@example.com Welcome
+}
+
+func ExampleServer_Fail() {
+	srv := plunktest.NewServer()
+	defer srv.Close()
+
+	p, _ := plunk.New(srv.ApiKey, &plunk.Config{BaseUrl: srv.BaseUrl})
+
+	srv.Fail("POST /track", plunktest.Failure{
+		Status:     http.StatusTooManyRequests,
+		RetryAfter: time.Second,
+		Times:      1,
+	})
+
+	_, err := p.TriggerEvent(plunk.EventPayload{Event: "signup", Email: "user@example.com"})
+	fmt.Println(err)
+	// Output: Plunk Error (Code: 429, Error: Too Many Requests, Message: Too Many Requests)
+}
@@ -0,0 +1,125 @@
+package plunktest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Failure describes how the fake answers requests to an endpoint instead of
+// handling them. Set it with Server.Fail.
+type Failure struct {
+	Status     int           // status code of the response, 500 when zero
+	Message    string        // message of the Plunk error body
+	Body       string        // raw body written instead of a Plunk error, e.g. malformed JSON
+	RetryAfter time.Duration // sent as the Retry-After header when set
+	Times      int           // number of requests to fail; zero fails every request until cleared
+}
+
+// TooManyRequests returns a failure that rate limits the next request, asking
+// the client to come back after retryAfter.
+func TooManyRequests(retryAfter time.Duration) Failure {
+	return Failure{
+		Status:     http.StatusTooManyRequests,
+		Message:    "Too many requests, please try again later",
+		RetryAfter: retryAfter,
+		Times:      1,
+	}
+}
+
+// InternalServerError returns a failure that answers the next request with a 500.
+func InternalServerError() Failure {
+	return Failure{
+		Status:  http.StatusInternalServerError,
+		Message: "Something went wrong",
+		Times:   1,
+	}
+}
+
+// MalformedJSON returns a failure that answers the next request with a 200
+// whose body is not valid JSON.
+func MalformedJSON() Failure {
+	return Failure{
+		Status: http.StatusOK,
+		Body:   `{"success": tru`,
+		Times:  1,
+	}
+}
+
+// Fail makes requests to route fail as described by f, replacing any failure
+// set before for the same route. A route is a method and path as the client
+// sends it, e.g. "POST /send" or "GET /contacts/count"; a path without a
+// method, e.g. "/contacts", matches every method.
+func (s *Server) Fail(route string, f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures == nil {
+		s.failures = map[string]*Failure{}
+	}
+
+	s.failures[route] = &f
+}
+
+// ClearFailures makes every endpoint behave normally again.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = nil
+}
+
+// Writes the failure set for the request's route, if any, and reports whether it did.
+func (s *Server) writeFailure(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	route := r.Method + " " + path
+
+	f, ok := s.failures[route]
+	if !ok {
+		route = path
+		f, ok = s.failures[route]
+	}
+
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+
+	failure := *f
+	if f.Times > 0 {
+		f.Times--
+		if f.Times == 0 {
+			delete(s.failures, route)
+		}
+	}
+	s.mu.Unlock()
+
+	status := failure.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	if failure.RetryAfter > 0 {
+		seconds := int((failure.RetryAfter + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+
+	if failure.Body != "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(failure.Body))
+
+		return true
+	}
+
+	message := failure.Message
+	if message == "" {
+		message = http.StatusText(status)
+	}
+
+	writeError(w, status, message)
+
+	return true
+}
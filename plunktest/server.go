@@ -0,0 +1,188 @@
+// Package plunktest provides an in-memory fake of the Plunk API for tests.
+//
+// The fake speaks the same JSON as api.useplunk.com, so a client pointed at it
+// behaves as it would against the real API without any network access:
+//
+//	srv := plunktest.NewServer()
+//	defer srv.Close()
+//
+//	p, err := plunk.New(srv.ApiKey, &plunk.Config{BaseUrl: srv.BaseUrl})
+package plunktest
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The API key NewServer accepts unless another one is set with NewServerWithKey.
+const DefaultApiKey = "sk_plunktest"
+
+// Server is a fake Plunk API backed by an httptest.Server. All state is kept
+// in memory and is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	BaseUrl string // pass as plunk.Config.BaseUrl
+	ApiKey  string // pass to plunk.New
+
+	mu       sync.Mutex
+	routes   map[string]http.HandlerFunc
+	failures map[string]*Failure
+	contacts []*contact
+	events   []*event
+	emails   []Email
+	tracked  []TrackedEvent
+
+	campaigns     []*campaign
+	sentCampaigns []SentCampaign
+	templates     []*template
+	actions       []*action
+}
+
+// NewServer starts a fake Plunk API that accepts DefaultApiKey.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	return NewServerWithKey(DefaultApiKey)
+}
+
+// NewServerWithKey starts a fake Plunk API that accepts the given API key.
+func NewServerWithKey(apiKey string) *Server {
+	s := &Server{ApiKey: apiKey}
+
+	s.routes = map[string]http.HandlerFunc{
+		"POST /send":                 s.send,
+		"POST /track":                s.track,
+		"GET /events":                s.getEvents,
+		"GET /events/:id":            s.getEvent,
+		"DELETE /events":             s.deleteEvent,
+		"GET /contacts":              s.getContacts,
+		"GET /contacts/count":        s.getContactsCount,
+		"GET /contacts/:id":          s.getContact,
+		"POST /contacts":             s.createContact,
+		"PUT /contacts":              s.updateContact,
+		"DELETE /contacts":           s.deleteContact,
+		"POST /contacts/subscribe":   s.subscribeContact,
+		"POST /contacts/unsubscribe": s.unsubscribeContact,
+		"POST /campaigns":            s.createCampaign,
+		"PUT /campaigns":             s.updateCampaign,
+		"DELETE /campaigns":          s.deleteCampaign,
+		"POST /campaigns/send":       s.sendCampaign,
+		"GET /templates":             s.getTemplates,
+		"GET /templates/:id":         s.getTemplate,
+		"POST /templates":            s.createTemplate,
+		"PUT /templates":             s.updateTemplate,
+		"DELETE /templates":          s.deleteTemplate,
+		"GET /actions":               s.getActions,
+		"GET /actions/:id":           s.getAction,
+		"POST /actions":              s.createAction,
+		"PUT /actions":               s.updateAction,
+		"DELETE /actions":            s.deleteAction,
+	}
+
+	s.Server = httptest.NewServer(s)
+	s.BaseUrl = s.Server.URL
+
+	return s
+}
+
+// ServeHTTP authenticates the request and dispatches it to the fake endpoint.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+s.ApiKey {
+		writeError(w, http.StatusUnauthorized, "Incorrect Bearer token specified")
+		return
+	}
+
+	if s.writeFailure(w, r) {
+		return
+	}
+
+	handler, ok := s.route(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Unknown route")
+		return
+	}
+
+	handler(w, r)
+}
+
+// Reset forgets all stored resources, recorded traffic and failures.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = nil
+	s.contacts = nil
+	s.events = nil
+	s.emails = nil
+	s.tracked = nil
+	s.campaigns = nil
+	s.sentCampaigns = nil
+	s.templates = nil
+	s.actions = nil
+}
+
+// Matches the request against the route table. A trailing path segment that
+// is not a route of its own is treated as an :id parameter.
+func (s *Server) route(r *http.Request) (http.HandlerFunc, bool) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if handler, ok := s.routes[r.Method+" "+path]; ok {
+		return handler, true
+	}
+
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return nil, false
+	}
+
+	handler, ok := s.routes[r.Method+" "+path[:i]+"/:id"]
+	return handler, ok
+}
+
+// Returns the :id parameter of the request path.
+func pathID(r *http.Request) string {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// Decodes the JSON request body into v, answering with a 400 on failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body: %s", err.Error()))
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Writes an error in the format the Plunk API uses.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"code":    status,
+		"error":   http.StatusText(status),
+		"message": message,
+		"time":    time.Now().UnixMilli(),
+	})
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
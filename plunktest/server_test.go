@@ -0,0 +1,186 @@
+package plunktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func do(t *testing.T, s *Server, method, path, apiKey, body string) (*http.Response, map[string]interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, s.BaseUrl+path, strings.NewReader(body))
+	assert.Nil(t, err)
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	return resp, result
+}
+
+func TestServerRequiresApiKey(t *testing.T) {
+	s := NewServerWithKey("sk_secret")
+	defer s.Close()
+
+	resp, result := do(t, s, http.MethodGet, "/contacts/count", "sk_wrong", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, float64(401), result["code"])
+	assert.Equal(t, "Unauthorized", result["error"])
+
+	resp, result = do(t, s, http.MethodGet, "/contacts/count", "sk_secret", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, float64(0), result["count"])
+}
+
+func TestServerUnknownRoute(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	resp, _ := do(t, s, http.MethodGet, "/nope", DefaultApiKey, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = do(t, s, http.MethodPatch, "/contacts", DefaultApiKey, "{}")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServerContactData(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	// data sent as an object is stored as a JSON string
+	resp, result := do(t, s, http.MethodPost, "/contacts", DefaultApiKey, `{"email":"a@example.com","data":{"plan":"pro"}}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"plan":"pro"}`, result["data"])
+
+	resp, result = do(t, s, http.MethodPut, "/contacts", DefaultApiKey, `{"id":"`+result["id"].(string)+`","data":"{\"plan\":\"free\"}"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"plan":"free"}`, result["data"])
+	assert.Equal(t, "a@example.com", result["email"])
+
+	resp, _ = do(t, s, http.MethodPost, "/contacts", DefaultApiKey, `{"email":"b@example.com","data":"not json"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, result = do(t, s, http.MethodPost, "/contacts", DefaultApiKey, `{"email":"A@example.com"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "Contact already exists", result["message"])
+}
+
+func TestServerTrackMergesData(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	_, first := do(t, s, http.MethodPost, "/track", DefaultApiKey, `{"event":"signup","email":"a@example.com","data":{"plan":"pro"}}`)
+	assert.Equal(t, true, first["success"])
+
+	_, second := do(t, s, http.MethodPost, "/track", DefaultApiKey, `{"event":"signup","email":"a@example.com","data":{"seats":3},"subscribed":false}`)
+	assert.Equal(t, first["event"], second["event"])
+	assert.Equal(t, first["contact"], second["contact"])
+
+	_, contact := do(t, s, http.MethodGet, "/contacts/"+first["contact"].(string), DefaultApiKey, "")
+	assert.Equal(t, `{"plan":"pro","seats":3}`, contact["data"])
+	assert.Equal(t, true, contact["subscribed"])
+}
+
+func TestServerRecordsEmailsAndEvents(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	do(t, s, http.MethodPost, "/send", DefaultApiKey, `{"to":"a@example.com","subject":"Hi","body":"Hello","name":"Acme"}`)
+	do(t, s, http.MethodPost, "/track", DefaultApiKey, `{"event":"signup","email":"b@example.com","data":{"plan":"pro"}}`)
+
+	emails := s.Emails()
+	assert.Len(t, emails, 1)
+	assert.Equal(t, "a@example.com", emails[0].To)
+	assert.Equal(t, "Hi", emails[0].Subject)
+	assert.Equal(t, "Hello", emails[0].Body)
+	assert.Equal(t, "Acme", emails[0].Name)
+
+	events := s.TrackedEvents()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "signup", events[0].Event)
+	assert.Equal(t, "b@example.com", events[0].Email)
+	assert.Equal(t, "pro", events[0].Data["plan"])
+	assert.True(t, events[0].Subscribed)
+
+	contacts := s.Contacts()
+	assert.Len(t, contacts, 2)
+	assert.False(t, contacts[0].Subscribed)
+
+	contact, ok := s.Contact("b@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"plan": "pro"}, contact.Data)
+
+	s.Reset()
+	assert.Empty(t, s.Emails())
+	assert.Empty(t, s.TrackedEvents())
+	assert.Empty(t, s.Contacts())
+}
+
+func TestServerAddContact(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	c := s.AddContact("a@example.com", true, map[string]interface{}{"plan": "pro"})
+	assert.NotEmpty(t, c.ID)
+
+	_, result := do(t, s, http.MethodGet, "/contacts/"+c.ID, DefaultApiKey, "")
+	assert.Equal(t, "a@example.com", result["email"])
+	assert.Equal(t, `{"plan":"pro"}`, result["data"])
+
+	// adding it again replaces it
+	again := s.AddContact("a@example.com", false, nil)
+	assert.Equal(t, c.ID, again.ID)
+	assert.False(t, again.Subscribed)
+	assert.Nil(t, again.Data)
+}
+
+func TestServerFail(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.Fail("POST /send", TooManyRequests(1500*time.Millisecond))
+
+	resp, result := do(t, s, http.MethodPost, "/send", DefaultApiKey, `{"to":"a@example.com","subject":"Hi","body":"Hello"}`)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+	assert.Equal(t, "Too Many Requests", result["error"])
+	assert.Empty(t, s.Emails())
+
+	// the failure only applied once
+	resp, _ = do(t, s, http.MethodPost, "/send", DefaultApiKey, `{"to":"a@example.com","subject":"Hi","body":"Hello"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, s.Emails(), 1)
+
+	// a path without a method fails every method until cleared
+	s.Fail("/contacts", Failure{Status: http.StatusBadGateway})
+	for i := 0; i < 3; i++ {
+		resp, result = do(t, s, http.MethodGet, "/contacts", DefaultApiKey, "")
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, "Bad Gateway", result["message"])
+	}
+
+	resp, _ = do(t, s, http.MethodGet, "/contacts/count", DefaultApiKey, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	s.ClearFailures()
+	resp, _ = do(t, s, http.MethodGet, "/contacts", DefaultApiKey, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	s.Fail("GET /contacts/count", MalformedJSON())
+	resp, result = do(t, s, http.MethodGet, "/contacts/count", DefaultApiKey, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, result)
+
+	s.Fail("DELETE /events", InternalServerError())
+	resp, _ = do(t, s, http.MethodDelete, "/events", DefaultApiKey, `{"id":"123"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
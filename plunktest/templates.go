@@ -0,0 +1,179 @@
+package plunktest
+
+import "net/http"
+
+// Template is a snapshot of a template stored by the fake.
+type Template struct {
+	ID      string
+	Subject string
+	Body    string
+	Type    string
+	Style   string
+}
+
+type template struct {
+	ID        string `json:"id"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	Type      string `json:"type"`
+	Style     string `json:"style"`
+	ProjectID string `json:"projectId"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type templatePayload struct {
+	ID      string `json:"id"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	Type    string `json:"type"`
+	Style   string `json:"style"`
+}
+
+// Templates returns every stored template, in the order they were created.
+func (s *Server) Templates() []Template {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	templates := make([]Template, len(s.templates))
+	for i, t := range s.templates {
+		templates[i] = Template{
+			ID:      t.ID,
+			Subject: t.Subject,
+			Body:    t.Body,
+			Type:    t.Type,
+			Style:   t.Style,
+		}
+	}
+
+	return templates
+}
+
+// Callers must hold s.mu.
+func (s *Server) templateByID(id string) *template {
+	for _, t := range s.templates {
+		if t.ID == id {
+			return t
+		}
+	}
+
+	return nil
+}
+
+func validateTemplate(w http.ResponseWriter, payload templatePayload) bool {
+	if payload.Subject == "" || payload.Body == "" {
+		writeError(w, http.StatusBadRequest, "Missing subject or body")
+		return false
+	}
+
+	if payload.Type != "MARKETING" && payload.Type != "TRANSACTIONAL" {
+		writeError(w, http.StatusBadRequest, "Invalid type")
+		return false
+	}
+
+	if payload.Style != "" && payload.Style != "PLUNK" && payload.Style != "HTML" {
+		writeError(w, http.StatusBadRequest, "Invalid style")
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) getTemplates(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	templates := make([]template, len(s.templates))
+	for i, t := range s.templates {
+		templates[i] = *t
+	}
+
+	writeJSON(w, http.StatusOK, templates)
+}
+
+func (s *Server) getTemplate(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.templateByID(pathID(r))
+	if t == nil {
+		writeError(w, http.StatusNotFound, "That template was not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) createTemplate(w http.ResponseWriter, r *http.Request) {
+	var payload templatePayload
+	if !decodeBody(w, r, &payload) || !validateTemplate(w, payload) {
+		return
+	}
+
+	if payload.Style == "" {
+		payload.Style = "PLUNK"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &template{
+		ID:        newID(),
+		Subject:   payload.Subject,
+		Body:      payload.Body,
+		Type:      payload.Type,
+		Style:     payload.Style,
+		ProjectID: projectID,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}
+	s.templates = append(s.templates, t)
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) updateTemplate(w http.ResponseWriter, r *http.Request) {
+	var payload templatePayload
+	if !decodeBody(w, r, &payload) || !validateTemplate(w, payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.templateByID(payload.ID)
+	if t == nil {
+		writeError(w, http.StatusNotFound, "That template was not found")
+		return
+	}
+
+	t.Subject = payload.Subject
+	t.Body = payload.Body
+	t.Type = payload.Type
+	if payload.Style != "" {
+		t.Style = payload.Style
+	}
+	t.UpdatedAt = now()
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) deleteTemplate(w http.ResponseWriter, r *http.Request) {
+	var payload templatePayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, t := range s.templates {
+		if t.ID == payload.ID {
+			s.templates = append(s.templates[:i], s.templates[i+1:]...)
+			writeJSON(w, http.StatusOK, t)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "That template was not found")
+}
@@ -0,0 +1,117 @@
+package plunktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Email is a transactional email the fake accepted through /send. An email
+// sent to several recipients is recorded once per recipient.
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+	Text    string
+	From    string
+	Name    string
+	ReplyTo string
+	Headers map[string]string
+	SentAt  time.Time
+
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to an Email, with its content decoded.
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content"`
+}
+
+type sendPayload struct {
+	To      recipients        `json:"to"`
+	Subject string            `json:"subject"`
+	Body    string            `json:"body"`
+	Text    string            `json:"text"`
+	From    string            `json:"from"`
+	Name    string            `json:"name"`
+	Reply   string            `json:"reply"`
+	Headers map[string]string `json:"headers"`
+
+	Attachments []Attachment `json:"attachments"`
+}
+
+// The "to" field, which is either a single address or a list of them.
+type recipients []string
+
+func (r *recipients) UnmarshalJSON(b []byte) error {
+	var address string
+	if err := json.Unmarshal(b, &address); err == nil {
+		*r = recipients{address}
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(r))
+}
+
+func (s *Server) send(w http.ResponseWriter, r *http.Request) {
+	var payload sendPayload
+	if !decodeBody(w, r, &payload) {
+		return
+	}
+
+	if len(payload.To) == 0 || payload.Subject == "" || payload.Body == "" {
+		writeError(w, http.StatusBadRequest, "Missing to, subject or body")
+		return
+	}
+
+	for _, to := range payload.To {
+		if to == "" {
+			writeError(w, http.StatusBadRequest, "Missing to, subject or body")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	emails := []map[string]interface{}{}
+	for _, to := range payload.To {
+		// sending to an unknown address creates an unsubscribed contact for it
+		c := s.upsertContact(to, false)
+
+		s.emails = append(s.emails, Email{
+			To:      to,
+			Subject: payload.Subject,
+			Body:    payload.Body,
+			Text:    payload.Text,
+			From:    payload.From,
+			Name:    payload.Name,
+			ReplyTo: payload.Reply,
+			Headers: payload.Headers,
+			SentAt:  time.Now(),
+
+			Attachments: payload.Attachments,
+		})
+
+		emails = append(emails, map[string]interface{}{
+			"contact": map[string]string{"id": c.ID, "email": c.Email},
+			"email":   to,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"emails":    emails,
+		"timestamp": now(),
+	})
+}
+
+// Emails returns every email sent through /send, oldest first.
+func (s *Server) Emails() []Email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Email{}, s.emails...)
+}
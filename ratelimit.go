@@ -0,0 +1,94 @@
+package plunk
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter decides when the next request may be sent. A single limiter is
+// shared by every call made through a Plunk client, retries included, so
+// Wait is called concurrently.
+//
+// TokenBucket only limits the process it runs in. A limiter shared by several
+// processes, e.g. on top of Redis, must take from one budget atomically, so
+// that two processes cannot both spend the last token, and must still return
+// as soon as ctx is done rather than wait for its backend.
+type RateLimiter interface {
+	// Blocks until a request may be sent, or returns the context's error if
+	// ctx is done first.
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is an in-process RateLimiter that allows bursts of up to burst
+// requests and refills at rate requests per second.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a full bucket. A burst below 1 is treated as 1. A
+// rate of 0 or less does not limit requests at all, like Config.RateLimit.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait takes a token from the bucket, sleeping until one is available.
+// Callers are served in the order they call Wait.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// the bucket would never refill
+	if b.rate <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	b.refill(time.Now())
+	b.tokens--
+
+	// a negative balance is a reservation on tokens that have yet to arrive
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	if err := sleep(ctx, wait); err != nil {
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+
+	b.tokens += elapsed * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
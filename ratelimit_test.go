@@ -0,0 +1,124 @@
+package plunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingLimiter struct {
+	calls int32
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	atomic.AddInt32(&l.calls, 1)
+	return ctx.Err()
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	bucket := NewTokenBucket(1, 5)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, bucket.Wait(context.Background()))
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestTokenBucketUnlimited(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		bucket := NewTokenBucket(rate, 1)
+
+		start := time.Now()
+		for i := 0; i < 10; i++ {
+			assert.Nil(t, bucket.Wait(context.Background()))
+		}
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+	}
+}
+
+func TestTokenBucketRate(t *testing.T) {
+	bucket := NewTokenBucket(100, 1)
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 11; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, bucket.Wait(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	// one token is available immediately, the other 10 arrive every 10ms
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestTokenBucketWaitHonorsContext(t *testing.T) {
+	bucket := NewTokenBucket(0.1, 1)
+	assert.Nil(t, bucket.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := bucket.Wait(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// the canceled reservation is handed back
+	assert.InDelta(t, 0, bucket.tokens, 0.1)
+}
+
+func TestRateLimiterSharedAcrossRequests(t *testing.T) {
+	server, calls := newCountingServer(t, func(n int32, w http.ResponseWriter, r *http.Request) {
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"code":503,"error":"Service Unavailable","message":"try again","time":0}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"count":1}`)
+	})
+
+	limiter := &countingLimiter{}
+	p, err := New("test-api-key", &Config{
+		BaseUrl:     server.URL,
+		Retry:       testRetryPolicy(),
+		RateLimiter: limiter,
+	})
+	assert.Nil(t, err)
+
+	_, err = p.GetContactsCount()
+	assert.Nil(t, err)
+
+	_, err = p.GetContact("123")
+	assert.Nil(t, err)
+
+	// every attempt, retries included, goes through the limiter
+	assert.Equal(t, atomic.LoadInt32(calls), atomic.LoadInt32(&limiter.calls))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&limiter.calls))
+}
+
+func TestNewWithRateLimit(t *testing.T) {
+	p, err := New("test-api-key", &Config{RateLimit: 10, RateBurst: 2})
+	assert.Nil(t, err)
+
+	bucket, ok := p.RateLimiter.(*TokenBucket)
+	assert.True(t, ok)
+	assert.Equal(t, float64(10), bucket.rate)
+	assert.Equal(t, float64(2), bucket.burst)
+
+	limiter := &countingLimiter{}
+	p, err = New("test-api-key", &Config{RateLimit: 10, RateLimiter: limiter})
+	assert.Nil(t, err)
+	assert.Equal(t, limiter, p.RateLimiter)
+}
@@ -2,9 +2,13 @@ package plunk
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"time"
 )
 
 type Request struct {
@@ -16,6 +20,11 @@ type SendConfig struct {
 	Url    string
 	Method string
 	Body   interface{}
+
+	// Sent as the Idempotency-Key header. Requests that are not idempotent by
+	// method (e.g. POST) are only retried when a key is set and the policy
+	// has RetryIdempotencyKeys.
+	IdempotencyKey string
 }
 
 func (p *Plunk) defaultReqConfig() *Request {
@@ -28,72 +37,154 @@ func (p *Plunk) defaultReqConfig() *Request {
 	}
 }
 
-func (p *Plunk) sendRequest(config SendConfig) (*http.Response, error) {
+func (p *Plunk) sendRequest(ctx context.Context, config SendConfig) (*http.Response, error) {
 	var (
 		resp *http.Response
 		err  error
 	)
 
-	url := config.Url
-	request := p.defaultReqConfig()
+	// logged with every entry so that the attempts of one request can be tied together
+	requestID := newRequestID()
+	fields := []interface{}{
+		"request_id", requestID,
+		"method", config.Method,
+		"path", requestPath(config.Url),
+	}
+
 	body, err := json.Marshal(config.Body)
 	if err != nil {
-		p.logError(fmt.Sprintf("error marshalling body: %s", err.Error()))
+		p.logError("error marshalling body", append(fields, "error", err)...)
 		return nil, err
 	}
 
-	data := bytes.NewBuffer(body)
+	if config.Method != http.MethodGet {
+		p.logDebug("sending request", append(fields, "body", p.redact(body))...)
+	}
 
-	if config.Method == http.MethodGet {
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			p.logError(fmt.Sprintf("error creating request: %s", err.Error()))
-			return nil, err
-		}
+	policy := p.retryPolicy()
+	retryable := config.retryable(policy)
+	start := time.Now()
 
-		for key, value := range request.Headers {
-			req.Header.Add(key, value)
-		}
+	attempt := 1
+	for ; ; attempt++ {
+		attemptStart := time.Now()
+		resp, err = p.doRequest(ctx, config, body)
+		p.logAttempt(fields, attempt, time.Since(attemptStart), resp, err)
 
-		resp, err = p.Client.Do(req)
-		if err != nil {
-			p.logError(fmt.Sprintf("error sending request: %s", err.Error()))
-			return nil, err
+		if attempt >= policy.MaxAttempts || !retryable || !policy.shouldRetry(resp, err) {
+			break
 		}
-	} else {
-		req, err := http.NewRequest(config.Method, url, data)
-		if err != nil {
-			p.logError(fmt.Sprintf("error creating request: %s", err.Error()))
-			return nil, err
+
+		delay := policy.backoff(attempt, resp)
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			// only Retry-After asks for longer; the caller gets the response
+			p.logInfo("not retrying, server asked to wait too long", append(fields, "attempt", attempt, "delay", delay)...)
+			break
 		}
 
-		for key, value := range request.Headers {
-			req.Header.Add(key, value)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
 		}
 
-		resp, err = p.Client.Do(req)
-		if err != nil {
-			p.logError(fmt.Sprintf("error sending request: %s", err.Error()))
+		p.logInfo("retrying request", append(fields, "attempt", attempt, "delay", delay)...)
+
+		if err := sleep(ctx, delay); err != nil {
 			return nil, err
 		}
 	}
 
+	fields = append(fields, "attempts", attempt, "latency", time.Since(start))
+
+	if err != nil {
+		p.logError("error sending request", append(fields, "error", err)...)
+		return nil, err
+	}
+
+	fields = append(fields, "status", resp.StatusCode)
+
 	err = parseAPIError(resp)
 	if err != nil {
+		p.logError("request failed", append(fields, "error_type", errorType(err), "error", err)...)
 		return nil, err
 	}
 
 	err = checkStatusCode(resp)
 	if err != nil {
-		p.logError(fmt.Sprintf("error checking status code: %s", err.Error()))
+		p.logError("error checking status code", append(fields, "error", err)...)
 		return nil, err
 	}
 
-	p.logInfo(fmt.Sprintf("made %s request to %s, status code: %d", config.Method, url, resp.StatusCode))
+	p.logInfo("request succeeded", fields...)
 
 	return resp, nil
 }
 
+func (p *Plunk) logAttempt(fields []interface{}, attempt int, latency time.Duration, resp *http.Response, err error) {
+	fields = append(fields[:len(fields):len(fields)], "attempt", attempt, "latency", latency)
+	if err != nil {
+		fields = append(fields, "error", err)
+	} else {
+		fields = append(fields, "status", resp.StatusCode)
+	}
+
+	p.logDebug("request attempt", fields...)
+}
+
+// Returns the path of a request URL, which unlike the full URL is safe to log.
+func requestPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return u.Path
+}
+
+// Makes a single attempt at the request described by config.
+func (p *Plunk) doRequest(ctx context.Context, config SendConfig, body []byte) (*http.Response, error) {
+	if p.RateLimiter != nil {
+		if err := p.RateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var data io.Reader
+	if config.Method != http.MethodGet {
+		data = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, config.Method, config.Url, data)
+	if err != nil {
+		return nil, err
+	}
+
+	request := p.defaultReqConfig()
+	for key, value := range request.Headers {
+		req.Header.Add(key, value)
+	}
+
+	if config.IdempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", config.IdempotencyKey)
+	}
+
+	return p.Client.Do(req)
+}
+
+// Reports whether the request can safely be sent more than once under policy.
+func (c SendConfig) retryable(policy *RetryPolicy) bool {
+	if c.IdempotencyKey != "" && policy.RetryIdempotencyKeys {
+		return true
+	}
+
+	switch c.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
 func checkStatusCode(resp *http.Response) error {
 	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
 		return nil
@@ -1,9 +1,13 @@
 package plunk
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -25,9 +29,8 @@ func TestDefaultReqConfig(t *testing.T) {
 }
 
 func TestSendRequest(t *testing.T) {
-	// create a new Plunk object with a mocked http.Client
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	// create a new Plunk object pointed at a fake Plunk API
+	p, _ := newTestClient(t)
 
 	// create a SendConfig object with a GET method and a mocked response body
 	config := SendConfig{
@@ -36,7 +39,7 @@ func TestSendRequest(t *testing.T) {
 		Body:   nil,
 	}
 
-	resp, err := p.sendRequest(config)
+	resp, err := p.sendRequest(context.Background(), config)
 	assert.Nil(t, err)
 	assert.NotNil(t, resp)
 
@@ -46,6 +49,45 @@ func TestSendRequest(t *testing.T) {
 	assert.NotNil(t, body)
 }
 
+func TestSendRequestContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    p.url(contactsCountEndpoint),
+		Method: http.MethodGet,
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// An already canceled context never reaches the server.
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	resp, err = p.sendRequest(ctx, SendConfig{
+		Url:    p.url(contactsEndpoint),
+		Method: http.MethodPost,
+		Body:   map[string]string{"id": "123"},
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
 func TestCheckStatusCode(t *testing.T) {
 	resp := &http.Response{StatusCode: 200, Status: "200 OK"}
 	err := checkStatusCode(resp)
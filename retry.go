@@ -0,0 +1,147 @@
+package plunk
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried.
+//
+// Only requests that are idempotent by method (GET, PUT, DELETE, ...) are
+// retried, so an email sent through /send never goes out twice because of a
+// retry.
+type RetryPolicy struct {
+	MaxAttempts int           // Total number of attempts, including the first one.
+	BaseDelay   time.Duration // Delay before the first retry, doubled on every attempt.
+	MaxDelay    time.Duration // Upper bound for the delay, Retry-After included.
+	Jitter      float64       // Fraction of the delay (0 to 1) that is randomized.
+
+	// Response status codes that are retried. A Retry-After header on a 429 or
+	// 503 response takes precedence over the computed backoff; when it asks
+	// for longer than MaxDelay, the response is returned instead of retried.
+	StatusCodes []int
+
+	// Decides whether a transport error is retried. When nil, every error
+	// except a canceled or expired context is retried.
+	RetryOnError func(err error) bool
+
+	// Also retry POST requests that carry an idempotency key. Plunk is not
+	// known to deduplicate requests on the Idempotency-Key header, so only
+	// set this for a server that does: otherwise a request that timed out
+	// after it was handled is sent, and an email delivered, twice.
+	RetryIdempotencyKeys bool
+}
+
+// DefaultRetryPolicy returns a policy that makes up to 3 attempts, backing off
+// exponentially from 500ms to at most 10s, on 429 and 5xx gateway errors.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.2,
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// Without a configured policy every request gets exactly one attempt.
+func (p *Plunk) retryPolicy() *RetryPolicy {
+	if p.Retry == nil || p.Retry.MaxAttempts < 1 {
+		return &RetryPolicy{MaxAttempts: 1}
+	}
+
+	return p.Retry
+}
+
+func (r *RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+
+		if r.RetryOnError != nil {
+			return r.RetryOnError(err)
+		}
+
+		return true
+	}
+
+	for _, code := range r.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns how long to wait after the given (1-based) attempt.
+func (r *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if d, ok := retryAfter(resp, time.Now()); ok {
+			return d
+		}
+	}
+
+	d := float64(r.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if r.MaxDelay > 0 && d > float64(r.MaxDelay) {
+		d = float64(r.MaxDelay)
+	}
+
+	if r.Jitter > 0 {
+		jitter := math.Min(r.Jitter, 1)
+		d -= d * jitter * rand.Float64()
+	}
+
+	return time.Duration(d)
+}
+
+// Parses the Retry-After header, which is either a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d, true
+		}
+
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// Waits for d, returning early with the context's error if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
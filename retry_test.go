@@ -0,0 +1,277 @@
+package plunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.MaxAttempts = 4
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 5 * time.Millisecond
+
+	return policy
+}
+
+// Returns a server that hands every request to handler along with its
+// 1-based sequence number, and a counter of the requests it received.
+func newCountingServer(t *testing.T, handler func(n int32, w http.ResponseWriter, r *http.Request)) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(atomic.AddInt32(&calls, 1), w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+// Returns a server that answers the first failures requests with status and
+// succeeds afterwards.
+func newFlakyServer(t *testing.T, failures int32, status int) (*httptest.Server, *int32) {
+	return newCountingServer(t, func(n int32, w http.ResponseWriter, r *http.Request) {
+		if n <= failures {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"code":%d,"error":"%s","message":"try again","time":0}`, status, http.StatusText(status))
+			return
+		}
+
+		fmt.Fprint(w, `{"count":42}`)
+	})
+}
+
+func TestSendRequestRetries(t *testing.T) {
+	server, calls := newFlakyServer(t, 3, http.StatusBadGateway)
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL, Retry: testRetryPolicy()})
+	assert.Nil(t, err)
+
+	count, err := p.GetContactsCount()
+	assert.Nil(t, err)
+	assert.Equal(t, 42, count)
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+}
+
+func TestSendRequestRetriesExhausted(t *testing.T) {
+	server, calls := newFlakyServer(t, 10, http.StatusInternalServerError)
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL, Retry: testRetryPolicy()})
+	assert.Nil(t, err)
+
+	_, err = p.GetContactsCount()
+	var plunkErr *CustomError
+	assert.True(t, errors.As(err, &plunkErr))
+	assert.Equal(t, http.StatusInternalServerError, plunkErr.Code)
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+}
+
+func TestSendRequestDoesNotRetryWithoutPolicy(t *testing.T) {
+	server, calls := newFlakyServer(t, 1, http.StatusServiceUnavailable)
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL})
+	assert.Nil(t, err)
+
+	_, err = p.GetContactsCount()
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestSendRequestDoesNotRetryNonRetryableStatus(t *testing.T) {
+	server, calls := newFlakyServer(t, 1, http.StatusBadRequest)
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL, Retry: testRetryPolicy()})
+	assert.Nil(t, err)
+
+	_, err = p.GetContactsCount()
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestSendRequestRetriesOnlyIdempotentRequests(t *testing.T) {
+	server, calls := newFlakyServer(t, 1, http.StatusBadGateway)
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL, Retry: testRetryPolicy()})
+	assert.Nil(t, err)
+
+	// a POST without an idempotency key must never be sent twice
+	_, err = p.sendRequest(context.Background(), SendConfig{
+		Url:    p.url(transactionalEmailEndpoint),
+		Method: http.MethodPost,
+		Body:   map[string]string{"to": "test@example.com"},
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	server, calls = newCountingServer(t, func(n int32, w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+
+		if n <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, `{"code":502,"error":"Bad Gateway","message":"try again","time":0}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"success":true}`)
+	})
+	p.BaseUrl = server.URL
+
+	// nor with a key, unless the policy trusts the server to honor it
+	_, err = p.sendRequest(context.Background(), SendConfig{
+		Url:            p.url(transactionalEmailEndpoint),
+		Method:         http.MethodPost,
+		Body:           map[string]string{"to": "test@example.com"},
+		IdempotencyKey: "key-1",
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	p.Retry.RetryIdempotencyKeys = true
+	resp, err := p.sendRequest(context.Background(), SendConfig{
+		Url:            p.url(transactionalEmailEndpoint),
+		Method:         http.MethodPost,
+		Body:           map[string]string{"to": "test@example.com"},
+		IdempotencyKey: "key-1",
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	assert.Equal(t, []string{"key-1", "key-1", "key-1"}, keys)
+}
+
+func TestSendRequestHonorsRetryAfter(t *testing.T) {
+	server, _ := newCountingServer(t, func(n int32, w http.ResponseWriter, r *http.Request) {
+		if n == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"code":429,"error":"Too Many Requests","message":"slow down","time":0}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"count":1}`)
+	})
+
+	policy := testRetryPolicy()
+	policy.MaxDelay = 2 * time.Second
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL, Retry: policy})
+	assert.Nil(t, err)
+
+	start := time.Now()
+	count, err := p.GetContactsCount()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestSendRequestRetryAfterAboveMaxDelay(t *testing.T) {
+	server, calls := newCountingServer(t, func(n int32, w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"code":429,"error":"Too Many Requests","message":"slow down","time":0}`)
+	})
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL, Retry: testRetryPolicy()})
+	assert.Nil(t, err)
+
+	start := time.Now()
+	_, err = p.GetContactsCount()
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	var apiErr *CustomError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.Code)
+}
+
+func TestSendRequestRetryStopsWhenContextDone(t *testing.T) {
+	server, calls := newFlakyServer(t, 10, http.StatusServiceUnavailable)
+
+	policy := testRetryPolicy()
+	policy.BaseDelay = time.Hour
+	policy.MaxDelay = time.Hour
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL, Retry: policy})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = p.GetContactsCountContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1, nil))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2, nil))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(3, nil))
+	assert.Equal(t, time.Second, policy.backoff(10, nil))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := policy.backoff(2, nil)
+		assert.GreaterOrEqual(t, d, 100*time.Millisecond)
+		assert.LessOrEqual(t, d, 200*time.Millisecond)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2023, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		header   string
+		expected time.Duration
+		ok       bool
+	}{
+		{header: "", ok: false},
+		{header: "3", expected: 3 * time.Second, ok: true},
+		{header: "-1", ok: false},
+		{header: "soon", ok: false},
+		{header: now.Add(30 * time.Second).Format(http.TimeFormat), expected: 30 * time.Second, ok: true},
+		{header: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0, ok: true},
+	}
+
+	for _, tc := range testCases {
+		resp := &http.Response{Header: http.Header{}}
+		if tc.header != "" {
+			resp.Header.Set("Retry-After", tc.header)
+		}
+
+		d, ok := retryAfter(resp, now)
+		assert.Equal(t, tc.ok, ok, tc.header)
+		assert.Equal(t, tc.expected, d, tc.header)
+	}
+}
+
+func TestSendConfigRetryable(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	assert.True(t, SendConfig{Method: http.MethodGet}.retryable(policy))
+	assert.True(t, SendConfig{Method: http.MethodPut}.retryable(policy))
+	assert.True(t, SendConfig{Method: http.MethodDelete}.retryable(policy))
+	assert.False(t, SendConfig{Method: http.MethodPost}.retryable(policy))
+	assert.False(t, SendConfig{Method: http.MethodPost, IdempotencyKey: "key"}.retryable(policy))
+
+	policy.RetryIdempotencyKeys = true
+	assert.False(t, SendConfig{Method: http.MethodPost}.retryable(policy))
+	assert.True(t, SendConfig{Method: http.MethodPost, IdempotencyKey: "key"}.retryable(policy))
+}
@@ -0,0 +1,232 @@
+package plunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Whether a template is meant for marketing emails or transactional ones.
+type TemplateType string
+
+const (
+	TemplateTypeMarketing     TemplateType = "MARKETING"
+	TemplateTypeTransactional TemplateType = "TRANSACTIONAL"
+)
+
+type Template struct {
+	ID        string       `json:"id"`
+	Subject   string       `json:"subject"`
+	Body      string       `json:"body"`
+	Type      TemplateType `json:"type"`
+	Style     Style        `json:"style"`
+	ProjectID string       `json:"projectId"`
+	CreatedAt string       `json:"createdAt"`
+	UpdatedAt string       `json:"updatedAt"`
+}
+
+type TemplatePayload struct {
+	Subject string       `json:"subject"`
+	Body    string       `json:"body"`
+	Type    TemplateType `json:"type"`
+	Style   Style        `json:"style,omitempty"`
+}
+
+var (
+	ErrMissingTemplateID      = errors.New("missing template id")
+	ErrInvalidTemplateType    = errors.New("invalid template type")
+	ErrCouldNotCreateTemplate = errors.New("could not create template")
+)
+
+func (t TemplatePayload) validate() error {
+	if t.Subject == "" {
+		return ErrMissingSubject
+	}
+
+	if t.Body == "" {
+		return ErrMissingBody
+	}
+
+	if t.Type != TemplateTypeMarketing && t.Type != TemplateTypeTransactional {
+		return ErrInvalidTemplateType
+	}
+
+	return nil
+}
+
+// Creates a template that can be used by actions, or sent with SendTransactionalEmail
+// by setting TransactionalEmailPayload.Template.
+func (p *Plunk) CreateTemplate(payload TemplatePayload) (*Template, error) {
+	return p.CreateTemplateContext(context.Background(), payload)
+}
+
+// Like CreateTemplate, but the request is bound to ctx.
+func (p *Plunk) CreateTemplateContext(ctx context.Context, payload TemplatePayload) (*Template, error) {
+	if err := payload.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &Template{}
+	url := p.url(templatesEndpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodPost,
+		Body:   payload,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.ID == "" {
+		return nil, ErrCouldNotCreateTemplate
+	}
+
+	p.logInfo("template created", "id", result.ID)
+
+	return result, nil
+}
+
+// Gets the details of a specific template.
+func (p *Plunk) GetTemplate(id string) (*Template, error) {
+	return p.GetTemplateContext(context.Background(), id)
+}
+
+// Like GetTemplate, but the request is bound to ctx.
+func (p *Plunk) GetTemplateContext(ctx context.Context, id string) (*Template, error) {
+	if id == "" {
+		return nil, ErrMissingTemplateID
+	}
+
+	result := &Template{}
+	endpoint := fmt.Sprintf("%s/%s", templatesEndpoint, id)
+	url := p.url(endpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodGet,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("template retrieved", "id", id)
+
+	return result, nil
+}
+
+// Get a list of all templates in your Plunk project.
+func (p *Plunk) GetTemplates() ([]*Template, error) {
+	return p.GetTemplatesContext(context.Background())
+}
+
+// Like GetTemplates, but the request is bound to ctx.
+func (p *Plunk) GetTemplatesContext(ctx context.Context) ([]*Template, error) {
+	result := []*Template{}
+	url := p.url(templatesEndpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodGet,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("templates retrieved", "count", len(result))
+
+	return result, nil
+}
+
+// Replaces the subject, body, type and style of a template.
+func (p *Plunk) UpdateTemplate(id string, payload TemplatePayload) (*Template, error) {
+	return p.UpdateTemplateContext(context.Background(), id, payload)
+}
+
+// Like UpdateTemplate, but the request is bound to ctx.
+func (p *Plunk) UpdateTemplateContext(ctx context.Context, id string, payload TemplatePayload) (*Template, error) {
+	if id == "" {
+		return nil, ErrMissingTemplateID
+	}
+
+	if err := payload.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &Template{}
+	url := p.url(templatesEndpoint)
+
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodPut,
+		Body: struct {
+			ID string `json:"id"`
+			TemplatePayload
+		}{id, payload},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("template updated", "id", id)
+
+	return result, nil
+}
+
+// Deletes a template.
+func (p *Plunk) DeleteTemplate(id string) (*Template, error) {
+	return p.DeleteTemplateContext(context.Background(), id)
+}
+
+// Like DeleteTemplate, but the request is bound to ctx.
+func (p *Plunk) DeleteTemplateContext(ctx context.Context, id string) (*Template, error) {
+	if id == "" {
+		return nil, ErrMissingTemplateID
+	}
+
+	url := p.url(templatesEndpoint)
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Url:    url,
+		Method: http.MethodDelete,
+		Body:   map[string]string{"id": id},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Template{}
+	err = decodeResponse(resp, result)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logInfo("template deleted", "id", id)
+
+	return result, nil
+}
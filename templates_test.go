@@ -0,0 +1,126 @@
+package plunk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTemplate(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	template, err := p.CreateTemplate(TemplatePayload{
+		Subject: "Reset your password",
+		Body:    "# Click the link below",
+		Type:    TemplateTypeTransactional,
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, template.ID)
+	assert.Equal(t, "Reset your password", template.Subject)
+	assert.Equal(t, TemplateTypeTransactional, template.Type)
+	assert.Equal(t, StylePlunk, template.Style)
+
+	testCases := []struct {
+		payload TemplatePayload
+		err     error
+	}{
+		{
+			payload: TemplatePayload{Body: "Body", Type: TemplateTypeMarketing},
+			err:     ErrMissingSubject,
+		},
+		{
+			payload: TemplatePayload{Subject: "Subject", Type: TemplateTypeMarketing},
+			err:     ErrMissingBody,
+		},
+		{
+			payload: TemplatePayload{Subject: "Subject", Body: "Body"},
+			err:     ErrInvalidTemplateType,
+		},
+		{
+			payload: TemplatePayload{Subject: "Subject", Body: "Body", Type: "NEWSLETTER"},
+			err:     ErrInvalidTemplateType,
+		},
+	}
+
+	for _, tc := range testCases {
+		template, err := p.CreateTemplate(tc.payload)
+		assert.Equal(t, tc.err, err)
+		assert.Nil(t, template)
+	}
+}
+
+func TestGetTemplates(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	templates, err := p.GetTemplates()
+	assert.Nil(t, err)
+	assert.Empty(t, templates)
+
+	created, err := p.CreateTemplate(TemplatePayload{
+		Subject: "Welcome",
+		Body:    "<h1>Welcome</h1>",
+		Type:    TemplateTypeMarketing,
+		Style:   StyleHTML,
+	})
+	assert.Nil(t, err)
+
+	template, err := p.GetTemplate(created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, created, template)
+
+	templates, err = p.GetTemplates()
+	assert.Nil(t, err)
+	assert.Equal(t, []*Template{created}, templates)
+
+	_, err = p.GetTemplate("")
+	assert.Equal(t, ErrMissingTemplateID, err)
+
+	_, err = p.GetTemplate("unknown")
+	assert.Equal(t, "Plunk Error (Code: 404, Error: Not Found, Message: That template was not found)", err.Error())
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	p, _ := newTestClient(t)
+
+	created, err := p.CreateTemplate(TemplatePayload{
+		Subject: "Welcome",
+		Body:    "# Welcome",
+		Type:    TemplateTypeMarketing,
+	})
+	assert.Nil(t, err)
+
+	updated, err := p.UpdateTemplate(created.ID, TemplatePayload{
+		Subject: "Welcome aboard",
+		Body:    "# Welcome aboard",
+		Type:    TemplateTypeTransactional,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, "Welcome aboard", updated.Subject)
+	assert.Equal(t, TemplateTypeTransactional, updated.Type)
+
+	_, err = p.UpdateTemplate("", TemplatePayload{})
+	assert.Equal(t, ErrMissingTemplateID, err)
+}
+
+func TestDeleteTemplate(t *testing.T) {
+	p, server := newTestClient(t)
+
+	created, err := p.CreateTemplate(TemplatePayload{
+		Subject: "Welcome",
+		Body:    "# Welcome",
+		Type:    TemplateTypeMarketing,
+	})
+	assert.Nil(t, err)
+
+	deleted, err := p.DeleteTemplate(created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, created.ID, deleted.ID)
+	assert.Empty(t, server.Templates())
+
+	_, err = p.DeleteTemplate(created.ID)
+	assert.NotNil(t, err)
+
+	_, err = p.DeleteTemplate("")
+	assert.Equal(t, ErrMissingTemplateID, err)
+}
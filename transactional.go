@@ -1,18 +1,66 @@
 package plunk
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
-	"sync"
 )
 
 type TransactionalEmailPayload struct {
-	To      string `json:"to"`
-	Subject string `json:"subject"`
-	Body    string `json:"body"`
-	From    string `json:"from,omitempty"`
-	Name    string `json:"name,omitempty"`
+	To      Recipients `json:"to"`
+	Subject string     `json:"subject"`
+	Body    string     `json:"body"`
+	From    string     `json:"from,omitempty"`
+	Name    string     `json:"name,omitempty"`
+	ReplyTo string     `json:"reply,omitempty"`
+
+	// Plain-text version of Body, for email clients that don't render HTML.
+	Text string `json:"text,omitempty"`
+
+	// How to read Body. When empty, Plunk guesses; see BodyFormat.
+	BodyFormat BodyFormat `json:"-"`
+
+	// Identifies this email across retries, e.g. an order ID. When a key has
+	// already been sent, the email is not sent again and the stored response
+	// is returned instead. See Config.Idempotency.
+	IdempotencyKey string `json:"-"`
+
+	// Extra headers, e.g. List-Unsubscribe. The API has no CC or BCC; send
+	// to every recipient through To instead.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Files sent with the email, at most MaxAttachmentSize in total.
+	// See NewAttachment and NewAttachmentFromFile.
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	// ID of a template to fill in Subject and Body from, when they are empty.
+	// The /send endpoint doesn't accept templates, so the client fetches it first.
+	Template string `json:"-"`
+}
+
+// Recipients are the addresses a transactional email is sent to. Every
+// recipient gets their own copy of the email. A single recipient is sent as
+// a plain string, the way the API has always accepted it.
+type Recipients []string
+
+func (r Recipients) MarshalJSON() ([]byte, error) {
+	if len(r) == 1 {
+		return json.Marshal(r[0])
+	}
+
+	return json.Marshal([]string(r))
+}
+
+// UnmarshalJSON accepts a single address as well as a list.
+func (r *Recipients) UnmarshalJSON(b []byte) error {
+	var address string
+	if err := json.Unmarshal(b, &address); err == nil {
+		*r = Recipients{address}
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(r))
 }
 
 type ContactInfo struct {
@@ -25,10 +73,18 @@ type EmailRecipient struct {
 	Email   string      `json:"email"`
 }
 
+// The outcome of sending a single email from a batch.
+type BatchResult struct {
+	Index    int // position of Payload in the batch
+	Payload  TransactionalEmailPayload
+	Response *TransactionalEmailResponse // nil when Err is set
+	Err      error
+}
+
 type TransactionalEmailResponse struct {
-	Success bool             `json:"success"`
-	Emails  []EmailRecipient `json:"emails"`
-	Timestamp string         `json:"timestamp"`
+	Success   bool             `json:"success"`
+	Emails    []EmailRecipient `json:"emails"`
+	Timestamp string           `json:"timestamp"`
 }
 
 var (
@@ -45,34 +101,111 @@ var (
 // # Using Markdown
 //
 // It is possible to use Markdown when sending a transactional email. Plunk will automatically apply the same styling as the email templates you make in the editor.
-// Any email with a body that starts with # will be treated as Markdown.
+// Any email with a body that starts with # will be treated as Markdown. Set BodyFormat to render Markdown that
+// starts with anything else, or to send HTML or text that starts with #.
 func (p *Plunk) SendTransactionalEmail(payload TransactionalEmailPayload) (*TransactionalEmailResponse, error) {
-	res, err := p.sendTransactionalEmails([]TransactionalEmailPayload{payload})
-	if err != nil {
-		return nil, err
-	}
+	return p.SendTransactionalEmailContext(context.Background(), payload)
+}
 
+// Like SendTransactionalEmail, but the request is bound to ctx.
+func (p *Plunk) SendTransactionalEmailContext(ctx context.Context, payload TransactionalEmailPayload) (*TransactionalEmailResponse, error) {
+	res, err := p.sendTransactionalEmails(ctx, []TransactionalEmailPayload{payload})
 	if len(res) == 0 {
+		if err != nil {
+			return nil, err
+		}
+
 		return nil, ErrEmptyResponse
 	}
 
-	return res[0], nil
+	if res[0].Err != nil {
+		return nil, res[0].Err
+	}
+
+	return res[0].Response, nil
 }
 
+// Sends every payload and returns the responses of the emails that were sent, in input order.
+// If any email could not be sent, the returned error is a *BatchError describing the failures.
 func (p *Plunk) SendMultipleTransactionalEmails(payload []TransactionalEmailPayload) ([]*TransactionalEmailResponse, error) {
-	return p.sendTransactionalEmails(payload)
+	return p.SendMultipleTransactionalEmailsContext(context.Background(), payload)
+}
+
+// Like SendMultipleTransactionalEmails, but every request is bound to ctx.
+// Emails that have not been sent yet when ctx is done are not sent at all.
+func (p *Plunk) SendMultipleTransactionalEmailsContext(ctx context.Context, payload []TransactionalEmailPayload) ([]*TransactionalEmailResponse, error) {
+	res, err := p.sendTransactionalEmails(ctx, payload)
+	if res == nil {
+		return nil, err
+	}
+
+	result := []*TransactionalEmailResponse{}
+	for _, r := range res {
+		if r.Err == nil {
+			result = append(result, r.Response)
+		}
+	}
+
+	return result, err
+}
+
+// Sends every payload and returns one result per payload, in input order.
+// The error is a *BatchError when at least one email failed, so callers can
+// retry or report the individual recipients.
+func (p *Plunk) SendTransactionalEmailBatch(payload []TransactionalEmailPayload) ([]BatchResult, error) {
+	return p.SendTransactionalEmailBatchContext(context.Background(), payload)
 }
 
-func (p *Plunk) sendTransactionalEmails(payload []TransactionalEmailPayload) ([]*TransactionalEmailResponse, error) {
-	sem := make(chan bool, 10)
-	var wg sync.WaitGroup
+// Like SendTransactionalEmailBatch, but every request is bound to ctx.
+func (p *Plunk) SendTransactionalEmailBatchContext(ctx context.Context, payload []TransactionalEmailPayload) ([]BatchResult, error) {
+	return p.sendTransactionalEmails(ctx, payload)
+}
 
-	// validate payload
-	for _, pl := range payload {
-		if pl.To == "" {
+func (p *Plunk) sendTransactionalEmails(ctx context.Context, payload []TransactionalEmailPayload) ([]BatchResult, error) {
+	// checks that don't depend on templates run first, so that an invalid
+	// batch fails before any template is fetched
+	payload = append([]TransactionalEmailPayload(nil), payload...)
+	for i := range payload {
+		pl := &payload[i]
+		if len(pl.To) == 0 {
 			return nil, ErrMissingTo
 		}
 
+		for _, to := range pl.To {
+			if to == "" {
+				return nil, ErrMissingTo
+			}
+		}
+
+		// normalized into a copy, so the caller's slice is left alone
+		pl.To = append(Recipients(nil), pl.To...)
+		for j := range pl.To {
+			if err := p.validateEmail("To", i, &pl.To[j]); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := p.validateEmail("From", i, &pl.From); err != nil {
+			return nil, err
+		}
+
+		if err := p.validateEmail("ReplyTo", i, &pl.ReplyTo); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if pl.Attachments, err = checkAttachments(i, pl.Attachments); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := p.applyTemplates(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range payload {
+		pl := &payload[i]
 		if pl.Subject == "" {
 			return nil, ErrMissingSubject
 		}
@@ -80,45 +213,101 @@ func (p *Plunk) sendTransactionalEmails(payload []TransactionalEmailPayload) ([]
 		if pl.Body == "" {
 			return nil, ErrMissingBody
 		}
+
+		if pl.Body, pl.Text, err = pl.formatBody(); err != nil {
+			return nil, err
+		}
 	}
 
-	result := []*TransactionalEmailResponse{}
+	results := make([]BatchResult, len(payload))
 	url := p.url(transactionalEmailEndpoint)
-	for _, pl := range payload {
-		wg.Add(1)
-		sem <- true
+	p.forEach(len(payload), func(i int) {
+		results[i] = BatchResult{Index: i, Payload: payload[i]}
 
-		go func(pl TransactionalEmailPayload) {
-			defer wg.Done()
-			defer func() { <-sem }()
+		// once ctx is done, the remaining emails are not sent at all
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			return
+		}
 
-			res := &TransactionalEmailResponse{}
-			resp, err := p.sendRequest(SendConfig{
-				Body:   pl,
-				Url:    url,
-				Method: http.MethodPost,
-			})
+		results[i].Response, results[i].Err = p.sendTransactionalEmail(ctx, url, payload[i])
+	})
 
-			if err != nil {
-				return
-			}
+	sent := len(results)
+	err = newBatchError(results)
+	if err != nil {
+		sent -= len(err.(*BatchError).Failed)
+		p.logError("could not send every transactional email", "error", err)
+	}
+
+	p.logInfo("transactional emails sent", "sent", sent, "total", len(results))
 
-			defer resp.Body.Close()
+	return results, err
+}
 
-			err = decodeResponse(resp, &res)
+// Returns a copy of payload where every email that references a template has
+// its empty subject and body filled in from it. Each template is fetched once.
+func (p *Plunk) applyTemplates(ctx context.Context, payload []TransactionalEmailPayload) ([]TransactionalEmailPayload, error) {
+	templates := map[string]*Template{}
+	result := make([]TransactionalEmailPayload, len(payload))
+
+	for i, pl := range payload {
+		result[i] = pl
+		if pl.Template == "" {
+			continue
+		}
+
+		template, ok := templates[pl.Template]
+		if !ok {
+			var err error
+			template, err = p.GetTemplateContext(ctx, pl.Template)
 			if err != nil {
-				return
+				return nil, err
 			}
 
-			result = append(result, res)
-		}(pl)
+			templates[pl.Template] = template
+		}
+
+		if result[i].Subject == "" {
+			result[i].Subject = template.Subject
+		}
+
+		if result[i].Body == "" {
+			result[i].Body = template.Body
+		}
 	}
 
-	wg.Wait()
+	return result, nil
+}
 
-	close(sem)
+func (p *Plunk) sendTransactionalEmail(ctx context.Context, url string, payload TransactionalEmailPayload) (*TransactionalEmailResponse, error) {
+	res := &TransactionalEmailResponse{}
+	if ok, err := p.storedResponse(ctx, transactionalEmailEndpoint, payload.IdempotencyKey, res); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
 
-	p.logInfo(fmt.Sprintf("Sent %d transactional emails", len(result)))
+		return res, nil
+	}
 
-	return result, nil
+	resp, err := p.sendRequest(ctx, SendConfig{
+		Body:           payload,
+		Url:            url,
+		Method:         http.MethodPost,
+		IdempotencyKey: payload.IdempotencyKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	err = decodeResponse(resp, res)
+	if err != nil {
+		return nil, err
+	}
+
+	p.storeResponse(transactionalEmailEndpoint, payload.IdempotencyKey, res)
+
+	return res, nil
 }
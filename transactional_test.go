@@ -1,17 +1,26 @@
 package plunk
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/kayode0x/plunk/plunktest"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestSendTransactionalEmail(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	payload := TransactionalEmailPayload{
-		To:      "test@example.com",
+		To:      []string{"test@example.com"},
 		Subject: "Test Subject",
 		Body:    "Test Body",
 	}
@@ -23,17 +32,16 @@ func TestSendTransactionalEmail(t *testing.T) {
 }
 
 func TestSendMultipleTransactionalEmails(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	payload := []TransactionalEmailPayload{
 		{
-			To:      "test@example.com",
+			To:      []string{"test@example.com"},
 			Subject: "Test Subject",
 			Body:    "Test Body",
 		},
 		{
-			To:      "test@example.com",
+			To:      []string{"test@example.com"},
 			Subject: "Test Subject 2",
 			Body:    "# Test Body 2",
 		},
@@ -51,8 +59,7 @@ func TestSendMultipleTransactionalEmails(t *testing.T) {
 }
 
 func TestSendTransactionalEmailWithInvalidPayload(t *testing.T) {
-	p, err := New(secretKey, opts)
-	assert.Nil(t, err)
+	p, _ := newTestClient(t)
 
 	testCases := []struct {
 		payload TransactionalEmailPayload
@@ -60,7 +67,7 @@ func TestSendTransactionalEmailWithInvalidPayload(t *testing.T) {
 	}{
 		{
 			payload: TransactionalEmailPayload{
-				To:      "",
+				To:      []string{""},
 				Subject: "Test Subject",
 				Body:    "Test Body",
 			},
@@ -68,7 +75,7 @@ func TestSendTransactionalEmailWithInvalidPayload(t *testing.T) {
 		},
 		{
 			payload: TransactionalEmailPayload{
-				To:      "test@example.com",
+				To:      []string{"test@example.com"},
 				Subject: "",
 				Body:    "Test Body",
 			},
@@ -76,7 +83,7 @@ func TestSendTransactionalEmailWithInvalidPayload(t *testing.T) {
 		},
 		{
 			payload: TransactionalEmailPayload{
-				To:      "test@example.com",
+				To:      []string{"test@example.com"},
 				Subject: "Test Subject",
 				Body:    "",
 			},
@@ -85,8 +92,308 @@ func TestSendTransactionalEmailWithInvalidPayload(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		_, err := p.sendTransactionalEmails([]TransactionalEmailPayload{tc.payload})
+		_, err := p.sendTransactionalEmails(context.Background(), []TransactionalEmailPayload{tc.payload})
 		assert.NotNil(t, err)
 		assert.Equal(t, err, tc.err)
 	}
 }
+
+func TestSendMultipleTransactionalEmailsContextCanceled(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		// the server only notices a client going away once the body is drained
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL})
+	assert.Nil(t, err)
+
+	payload := make([]TransactionalEmailPayload, 25)
+	for i := range payload {
+		payload[i] = TransactionalEmailPayload{
+			To:      []string{"test@example.com"},
+			Subject: "Test Subject",
+			Body:    "Test Body",
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	res, err := p.SendMultipleTransactionalEmailsContext(ctx, payload)
+	assert.Empty(t, res)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// only the first batch of workers should have reached the server
+	assert.LessOrEqual(t, atomic.LoadInt32(&calls), int32(10))
+}
+
+func TestSendTransactionalEmailBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload TransactionalEmailPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		if payload.To[0] == "bounce@example.com" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code":400,"error":"Bad Request","message":"Invalid recipient","time":0}`)
+			return
+		}
+
+		fmt.Fprintf(w, `{"success":true,"emails":[{"contact":{"id":"id-%s","email":"%s"},"email":"%s"}]}`, payload.To[0], payload.To[0], payload.To[0])
+	}))
+	defer server.Close()
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL})
+	assert.Nil(t, err)
+
+	recipients := []string{
+		"a@example.com",
+		"bounce@example.com",
+		"b@example.com",
+		"bounce@example.com",
+		"c@example.com",
+	}
+	payload := []TransactionalEmailPayload{}
+	for _, to := range recipients {
+		payload = append(payload, TransactionalEmailPayload{
+			To:      []string{to},
+			Subject: "Test Subject",
+			Body:    "Test Body",
+		})
+	}
+
+	results, err := p.SendTransactionalEmailBatch(payload)
+	assert.Len(t, results, len(payload))
+
+	for i, r := range results {
+		assert.Equal(t, i, r.Index)
+		assert.Equal(t, Recipients{recipients[i]}, r.Payload.To)
+
+		if recipients[i] == "bounce@example.com" {
+			assert.Nil(t, r.Response)
+			assert.NotNil(t, r.Err)
+		} else {
+			assert.Nil(t, r.Err)
+			assert.Equal(t, recipients[i], r.Response.Emails[0].Email)
+		}
+	}
+
+	var batchErr *BatchError
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Equal(t, len(payload), batchErr.Total)
+	assert.Len(t, batchErr.Failed, 2)
+	assert.Equal(t, 1, batchErr.Failed[0].Index)
+	assert.Equal(t, 3, batchErr.Failed[1].Index)
+
+	var plunkErr *CustomError
+	assert.True(t, errors.As(err, &plunkErr))
+	assert.Equal(t, "Invalid recipient", plunkErr.Message)
+
+	// the compatible API returns only the successful responses, in order
+	res, err := p.SendMultipleTransactionalEmails(payload)
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Len(t, res, 3)
+	assert.Equal(t, "a@example.com", res[0].Emails[0].Email)
+	assert.Equal(t, "b@example.com", res[1].Emails[0].Email)
+	assert.Equal(t, "c@example.com", res[2].Emails[0].Email)
+
+	// a single send surfaces the underlying error rather than a batch error
+	_, err = p.SendTransactionalEmail(payload[1])
+	assert.True(t, errors.As(err, &plunkErr))
+	assert.False(t, errors.As(err, &batchErr))
+}
+
+// Run with -race: results are collected from many goroutines at once.
+func TestSendMultipleTransactionalEmailsConcurrently(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		var payload TransactionalEmailPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		fmt.Fprintf(w, `{"success":true,"emails":[{"contact":{"id":"id","email":"%s"},"email":"%s"}]}`, payload.To[0], payload.To[0])
+	}))
+	defer server.Close()
+
+	p, err := New("test-api-key", &Config{BaseUrl: server.URL, Concurrency: 25})
+	assert.Nil(t, err)
+
+	payload := make([]TransactionalEmailPayload, 500)
+	for i := range payload {
+		payload[i] = TransactionalEmailPayload{
+			To:      []string{fmt.Sprintf("user%d@example.com", i)},
+			Subject: "Test Subject",
+			Body:    "Test Body",
+		}
+	}
+
+	res, err := p.SendMultipleTransactionalEmails(payload)
+	assert.Nil(t, err)
+	assert.Len(t, res, len(payload))
+	assert.Equal(t, int32(len(payload)), atomic.LoadInt32(&calls))
+
+	for i, r := range res {
+		assert.True(t, r.Success)
+		assert.Equal(t, payload[i].To[0], r.Emails[0].Email)
+	}
+}
+
+func TestSendTransactionalEmailWithTemplate(t *testing.T) {
+	p, server := newTestClient(t)
+
+	template, err := p.CreateTemplate(TemplatePayload{
+		Subject: "Reset your password",
+		Body:    "# Click the link below",
+		Type:    TemplateTypeTransactional,
+	})
+	assert.Nil(t, err)
+
+	res, err := p.SendMultipleTransactionalEmails([]TransactionalEmailPayload{
+		{To: []string{"a@example.com"}, Template: template.ID},
+		{To: []string{"b@example.com"}, Template: template.ID, Subject: "Custom subject"},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, res, 2)
+
+	emails := server.Emails()
+	assert.Len(t, emails, 2)
+	assert.Equal(t, "Reset your password", emails[0].Subject)
+	assert.Equal(t, "# Click the link below", emails[0].Body)
+	assert.Equal(t, "Custom subject", emails[1].Subject)
+	assert.Equal(t, "# Click the link below", emails[1].Body)
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{To: []string{"a@example.com"}, Template: "unknown"})
+	assert.Equal(t, "Plunk Error (Code: 404, Error: Not Found, Message: That template was not found)", err.Error())
+	assert.Len(t, server.Emails(), 2)
+}
+
+func TestSendTransactionalEmailChecksBeforeFetchingTemplates(t *testing.T) {
+	p, server := newTestClient(t)
+	p.Validator = &EmailValidator{}
+
+	template, err := p.CreateTemplate(TemplatePayload{
+		Subject: "Reset your password",
+		Body:    "Click the link below",
+		Type:    TemplateTypeTransactional,
+	})
+	assert.Nil(t, err)
+
+	// templates can't be fetched, but the batch fails on its own mistakes first
+	server.Fail("GET /templates/"+template.ID, plunktest.Failure{Status: 500, Message: "Something went wrong"})
+
+	_, err = p.SendTransactionalEmailBatch([]TransactionalEmailPayload{
+		{To: []string{"a@example.com"}, Template: template.ID},
+		{Template: template.ID},
+	})
+	assert.Equal(t, ErrMissingTo, err)
+
+	_, err = p.SendTransactionalEmailBatch([]TransactionalEmailPayload{
+		{To: []string{"a@example.com"}, Template: template.ID},
+		{To: []string{"not an address"}, Template: template.ID},
+	})
+	assert.True(t, errors.Is(err, ErrInvalidEmail))
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:          []string{"a@example.com"},
+		Template:    template.ID,
+		Attachments: []Attachment{{Filename: "empty.txt"}},
+	})
+	assert.Equal(t, ErrEmptyAttachment, err)
+
+	// subject and body are checked once templates are applied
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{To: []string{"a@example.com"}, Body: "Body"})
+	assert.Equal(t, ErrMissingSubject, err)
+
+	assert.Empty(t, server.Emails())
+}
+
+func TestRecipientsJSON(t *testing.T) {
+	b, err := json.Marshal(TransactionalEmailPayload{To: Recipients{"a@example.com"}})
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"to":"a@example.com"`)
+
+	b, err = json.Marshal(TransactionalEmailPayload{To: Recipients{"a@example.com", "b@example.com"}})
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"to":["a@example.com","b@example.com"]`)
+
+	var payload TransactionalEmailPayload
+	assert.Nil(t, json.Unmarshal([]byte(`{"to":"a@example.com"}`), &payload))
+	assert.Equal(t, Recipients{"a@example.com"}, payload.To)
+
+	assert.Nil(t, json.Unmarshal([]byte(`{"to":["a@example.com","b@example.com"]}`), &payload))
+	assert.Equal(t, Recipients{"a@example.com", "b@example.com"}, payload.To)
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"to":1}`), &payload))
+}
+
+func TestSendTransactionalEmailToMultipleRecipients(t *testing.T) {
+	p, server := newTestClient(t)
+
+	res, err := p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+		ReplyTo: "support@example.com",
+		Headers: map[string]string{"List-Unsubscribe": "<https://example.com/unsubscribe>"},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, res.Emails, 2)
+	assert.Equal(t, "a@example.com", res.Emails[0].Email)
+	assert.Equal(t, "b@example.com", res.Emails[1].Email)
+
+	emails := server.Emails()
+	assert.Len(t, emails, 2)
+	for _, email := range emails {
+		assert.Equal(t, "support@example.com", email.ReplyTo)
+		assert.Equal(t, "<https://example.com/unsubscribe>", email.Headers["List-Unsubscribe"])
+	}
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:      []string{"a@example.com", ""},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	})
+	assert.Equal(t, ErrMissingTo, err)
+}
+
+func TestValidatorChecksEveryRecipient(t *testing.T) {
+	p, server := newTestClient(t)
+	p.Validator = &EmailValidator{}
+
+	to := Recipients{"A@Example.com", "b@EXAMPLE.com"}
+	_, err := p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:      to,
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "A@example.com", server.Emails()[0].To)
+	assert.Equal(t, "b@example.com", server.Emails()[1].To)
+
+	// the caller's recipients are not modified
+	assert.Equal(t, Recipients{"A@Example.com", "b@EXAMPLE.com"}, to)
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:      []string{"a@example.com", "not an email"},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	})
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "To", validationErr.Field)
+	assert.Equal(t, "not an email", validationErr.Value)
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:      []string{"a@example.com"},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+		ReplyTo: "reply@",
+	})
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "ReplyTo", validationErr.Field)
+}
@@ -0,0 +1,165 @@
+package plunk
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+var (
+	ErrInvalidEmail    = errors.New("invalid email address")
+	ErrDisposableEmail = errors.New("disposable email address")
+)
+
+// ValidationError is returned when Config.Validator rejects an email address.
+// It wraps ErrInvalidEmail or ErrDisposableEmail.
+type ValidationError struct {
+	Field string // name of the payload field, e.g. "To"
+	Index int    // position of the payload in the batch, 0 for single payloads
+	Value string // the rejected address
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s of payload %d: %q: %s", e.Field, e.Index, e.Value, e.Err.Error())
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// DomainList reports whether a domain is on a list, e.g. of disposable email providers.
+type DomainList interface {
+	// Called with a lowercased ASCII domain, with IDNs in punycode.
+	Contains(domain string) bool
+}
+
+// DomainSet is an in-memory DomainList. Subdomains of a listed domain are on
+// the list too.
+type DomainSet map[string]struct{}
+
+// NewDomainSet returns a set of the given domains, normalized the way
+// EmailValidator normalizes addresses.
+func NewDomainSet(domains ...string) DomainSet {
+	set := DomainSet{}
+	for _, domain := range domains {
+		if d, err := normalizeDomain(domain); err == nil && d != "" {
+			set[d] = struct{}{}
+		}
+	}
+
+	return set
+}
+
+// ReadDomainSet reads a list of domains, one per line. Blank lines and lines
+// starting with # are ignored.
+func ReadDomainSet(r io.Reader) (DomainSet, error) {
+	domains := []string{}
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		domains = append(domains, line)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewDomainSet(domains...), nil
+}
+
+func (s DomainSet) Contains(domain string) bool {
+	for {
+		if _, ok := s[domain]; ok {
+			return true
+		}
+
+		i := strings.IndexByte(domain, '.')
+		if i < 0 {
+			return false
+		}
+
+		domain = domain[i+1:]
+	}
+}
+
+// EmailValidator checks email addresses before they are sent to Plunk, and
+// normalizes them. Set it as Config.Validator to check the addresses of every
+// transactional email, event and new contact.
+type EmailValidator struct {
+	// Addresses on these domains are rejected with ErrDisposableEmail. Nil
+	// accepts every domain.
+	Disposable DomainList
+}
+
+// Normalize checks that email is a bare address as defined by RFC 5322, and
+// returns it with its domain lowercased and, for IDNs, converted to punycode.
+// The local part is kept as is.
+func (v *EmailValidator) Normalize(email string) (string, error) {
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidEmail, err.Error())
+	}
+
+	// display names and comments, as in "Name <user@example.com>", are not accepted
+	email = strings.TrimSpace(email)
+	if addr.Name != "" || strings.ContainsAny(email, "<>()") {
+		return "", ErrInvalidEmail
+	}
+
+	// the local part is kept as written, quotes included
+	at := strings.LastIndexByte(email, '@')
+	local, domain := email[:at], email[at+1:]
+
+	domain, err = normalizeDomain(domain)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidEmail, err.Error())
+	}
+
+	if !strings.Contains(domain, ".") {
+		return "", fmt.Errorf("%w: domain %q is not fully qualified", ErrInvalidEmail, domain)
+	}
+
+	if v.Disposable != nil && v.Disposable.Contains(domain) {
+		return "", ErrDisposableEmail
+	}
+
+	return local + "@" + domain, nil
+}
+
+func normalizeDomain(domain string) (string, error) {
+	domain, err := idna.Lookup.ToASCII(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(domain), nil
+}
+
+// Normalizes the address in a payload field when a validator is configured.
+// Empty addresses are left to the payload's own checks.
+func (p *Plunk) validateEmail(field string, index int, email *string) error {
+	if p.Validator == nil || *email == "" {
+		return nil
+	}
+
+	normalized, err := p.Validator.Normalize(*email)
+	if err != nil {
+		p.logError("invalid email address", "field", field, "index", index, "error", err)
+		return &ValidationError{Field: field, Index: index, Value: *email, Err: err}
+	}
+
+	*email = normalized
+
+	return nil
+}
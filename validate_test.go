@@ -0,0 +1,146 @@
+package plunk
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kayode0x/plunk/plunktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailValidatorNormalize(t *testing.T) {
+	v := &EmailValidator{}
+
+	testCases := []struct {
+		email    string
+		expected string
+		err      error
+	}{
+		{email: "user@example.com", expected: "user@example.com"},
+		{email: "User.Name+tag@Example.COM", expected: "User.Name+tag@example.com"},
+		{email: " user@example.com ", expected: "user@example.com"},
+		{email: "user@bücher.example", expected: "user@xn--bcher-kva.example"},
+		{email: "user@BÜCHER.example", expected: "user@xn--bcher-kva.example"},
+		{email: `"john doe"@example.com`, expected: `"john doe"@example.com`},
+		{email: "user@example.com.", err: ErrInvalidEmail},
+		{email: "John <user@example.com>", err: ErrInvalidEmail},
+		{email: "user", err: ErrInvalidEmail},
+		{email: "user@", err: ErrInvalidEmail},
+		{email: "user@@example.com", err: ErrInvalidEmail},
+		{email: "user@localhost", err: ErrInvalidEmail},
+		{email: "user@exa mple.com", err: ErrInvalidEmail},
+	}
+
+	for _, tc := range testCases {
+		email, err := v.Normalize(tc.email)
+		if tc.err != nil {
+			assert.True(t, errors.Is(err, tc.err), "%s: %v", tc.email, err)
+			continue
+		}
+
+		assert.Nil(t, err, tc.email)
+		assert.Equal(t, tc.expected, email)
+	}
+}
+
+func TestDomainSet(t *testing.T) {
+	set, err := ReadDomainSet(strings.NewReader("# disposable domains\nMailinator.com\n\n  tempmail.example \nbücher.example\n"))
+	assert.Nil(t, err)
+	assert.Len(t, set, 3)
+
+	assert.True(t, set.Contains("mailinator.com"))
+	assert.True(t, set.Contains("eu.mailinator.com"))
+	assert.True(t, set.Contains("tempmail.example"))
+	assert.True(t, set.Contains("xn--bcher-kva.example"))
+	assert.False(t, set.Contains("example.com"))
+	assert.False(t, set.Contains("notmailinator.com"))
+
+	v := &EmailValidator{Disposable: set}
+
+	_, err = v.Normalize("user@Mailinator.com")
+	assert.Equal(t, ErrDisposableEmail, err)
+
+	_, err = v.Normalize("user@bücher.example")
+	assert.Equal(t, ErrDisposableEmail, err)
+
+	email, err := v.Normalize("user@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "user@example.com", email)
+}
+
+func newValidatingClient(t *testing.T) (*Plunk, *plunktest.Server) {
+	t.Helper()
+
+	srv := plunktest.NewServer()
+	t.Cleanup(srv.Close)
+
+	p, err := New(srv.ApiKey, &Config{
+		BaseUrl:   srv.BaseUrl,
+		Validator: &EmailValidator{Disposable: NewDomainSet("mailinator.com")},
+	})
+	assert.Nil(t, err)
+
+	return p, srv
+}
+
+func TestValidatorTransactionalEmails(t *testing.T) {
+	p, srv := newValidatingClient(t)
+
+	_, err := p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:      []string{"user@Bücher.example"},
+		Subject: "Subject",
+		Body:    "Body",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "user@xn--bcher-kva.example", srv.Emails()[0].To)
+
+	_, err = p.SendTransactionalEmailBatch([]TransactionalEmailPayload{
+		{To: []string{"first@example.com"}, Subject: "Subject", Body: "Body"},
+		{To: []string{"second@example.com"}, Subject: "Subject", Body: "Body", From: "not an email"},
+	})
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "From", validationErr.Field)
+	assert.Equal(t, 1, validationErr.Index)
+	assert.Equal(t, "not an email", validationErr.Value)
+	assert.True(t, errors.Is(err, ErrInvalidEmail))
+
+	// nothing is sent when any address is invalid
+	assert.Len(t, srv.Emails(), 1)
+
+	_, err = p.SendTransactionalEmail(TransactionalEmailPayload{
+		To:      []string{"user@mailinator.com"},
+		Subject: "Subject",
+		Body:    "Body",
+	})
+	assert.True(t, errors.Is(err, ErrDisposableEmail))
+	assert.Equal(t, `To of payload 0: "user@mailinator.com": disposable email address`, err.Error())
+}
+
+func TestValidatorEventsAndContacts(t *testing.T) {
+	p, srv := newValidatingClient(t)
+
+	_, err := p.TriggerEvent(EventPayload{Event: "signup", Email: "User@EXAMPLE.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, "User@example.com", srv.TrackedEvents()[0].Email)
+
+	_, err = p.TriggerEvent(EventPayload{Event: "signup", Email: "user@example"})
+	assert.True(t, errors.Is(err, ErrInvalidEmail))
+
+	contact, err := p.CreateContact(CreateContactPayload{Email: "new@Example.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, "new@example.com", contact.Email)
+
+	_, err = p.CreateContact(CreateContactPayload{Email: "new@mailinator.com"})
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "Email", validationErr.Field)
+	assert.Equal(t, ErrDisposableEmail, validationErr.Err)
+
+	// without a validator, addresses are sent as they are
+	p.Validator = nil
+	_, err = p.TriggerEvent(EventPayload{Event: "signup", Email: "user@example"})
+	assert.Nil(t, err)
+}